@@ -10,14 +10,42 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/mcpsek/mcpsek/internal/api"
+	"github.com/mcpsek/mcpsek/internal/classifier"
 	"github.com/mcpsek/mcpsek/internal/config"
 	"github.com/mcpsek/mcpsek/internal/database"
 	"github.com/mcpsek/mcpsek/internal/scanner"
 	"github.com/mcpsek/mcpsek/internal/scheduler"
+	"github.com/mcpsek/mcpsek/internal/shodan"
 	"github.com/mcpsek/mcpsek/internal/web"
 )
 
 func main() {
+	// Subcommands
+	if len(os.Args) > 1 && os.Args[1] == "train" {
+		if err := runTrain(os.Args[2:]); err != nil {
+			log.Fatalf("Training failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "scan" {
+		if err := runScan(os.Args[2:]); err != nil {
+			log.Fatalf("Scan failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "proxy" {
+		if err := runProxy(os.Args[2:]); err != nil {
+			log.Fatalf("Proxy failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "mcp" {
+		if err := runMCP(os.Args[2:]); err != nil {
+			log.Fatalf("MCP server failed: %v", err)
+		}
+		return
+	}
+
 	log.Println("mcpsek starting...")
 
 	// Load configuration
@@ -47,12 +75,21 @@ func main() {
 	}
 
 	// Initialize scanner
-	scn := scanner.New(cfg.CloneDir, db)
+	scn := newScanner(cfg, db)
+
+	// Initialize observed exposure lookups (optional)
+	var observer *shodan.Observer
+	if cfg.ShodanAPIKey != "" {
+		client := shodan.NewClient(cfg.ShodanAPIKey, cfg.ShodanBaseURL, cfg.ShodanRateLimit)
+		observer = shodan.NewObserver(client, db, cfg.ShodanCacheTTL)
+		log.Printf("Shodan exposure lookups enabled (%s)", cfg.ShodanBaseURL)
+	}
 
 	// Initialize scheduler
 	sched := scheduler.New(
 		db,
 		scn,
+		observer,
 		cfg.GitHubToken,
 		cfg.ScanWorkers,
 		cfg.ScanInterval,
@@ -70,7 +107,7 @@ func main() {
 	}()
 
 	// Initialize API
-	apiHandler := api.New(db)
+	apiHandler := api.New(db, cfg.AdminToken)
 
 	// Initialize web UI
 	webHandler, err := web.New(db, "internal/web/templates")
@@ -119,3 +156,34 @@ func main() {
 
 	log.Println("mcpsek stopped")
 }
+
+// newScanner builds the scanner from configuration
+func newScanner(cfg *config.Config, db *database.DB) *scanner.Scanner {
+	// Load the prompt-injection classifier (optional)
+	var model *classifier.Model
+	if cfg.ClassifierModel != "" {
+		var err error
+		model, err = classifier.Load(cfg.ClassifierModel)
+		if err != nil {
+			log.Printf("Injection classifier disabled: %v", err)
+		}
+	}
+
+	weights := make(scanner.ContextWeights, len(cfg.ContextWeights))
+	for class, weight := range cfg.ContextWeights {
+		weights[scanner.FileClass(class)] = weight
+	}
+
+	return scanner.New(cfg.CloneDir, db, scanner.Options{
+		ContextWeights:   weights,
+		MinConfidence:    &cfg.MinConfidence,
+		ScoreUnreachable: cfg.ScoreUnreachable,
+		DeepScan:         cfg.DeepScan,
+		HistoryLimits: scanner.HistoryLimits{
+			MaxCommits: cfg.HistoryMaxCommits,
+			MaxBytes:   cfg.HistoryMaxBytes,
+		},
+		Classifier:          model,
+		ClassifierThreshold: cfg.ClassifierThreshold,
+	})
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/mcpsek/mcpsek/internal/classifier"
+	"github.com/mcpsek/mcpsek/internal/config"
+	"github.com/mcpsek/mcpsek/internal/database"
+	"github.com/mcpsek/mcpsek/internal/mcpserver"
+)
+
+// runMCP serves mcpsek's index as an MCP server over stdio, or Streamable HTTP with -http
+func runMCP(args []string) error {
+	fs := flag.NewFlagSet("mcp", flag.ExitOnError)
+	listen := fs.String("http", "", "serve Streamable HTTP at /mcp on this address instead of stdio, e.g. 127.0.0.1:8932")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mcpsek mcp [-http ADDR]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	db, err := database.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return fmt.Errorf("connect to database: %w", err)
+	}
+	defer db.Close()
+
+	// The classifier is optional, as it is for scans
+	var model *classifier.Model
+	if cfg.ClassifierModel != "" {
+		if model, err = classifier.Load(cfg.ClassifierModel); err != nil {
+			log.Printf("Injection classifier disabled: %v", err)
+		}
+	}
+
+	server, err := mcpserver.New(db, model, cfg.ClassifierThreshold)
+	if err != nil {
+		return err
+	}
+
+	if *listen == "" {
+		// stdout carries the protocol, so logs stay on stderr
+		return server.ServeStdio(ctx, os.Stdin, os.Stdout)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", server)
+	httpServer := &http.Server{Addr: *listen, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		httpServer.Shutdown(context.Background())
+	}()
+
+	log.Printf("mcpsek MCP server listening on %s/mcp", *listen)
+	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("serve: %w", err)
+	}
+	return nil
+}
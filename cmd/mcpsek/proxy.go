@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/mcpsek/mcpsek/internal/classifier"
+	"github.com/mcpsek/mcpsek/internal/config"
+	"github.com/mcpsek/mcpsek/internal/proxy"
+)
+
+// runProxy sits between an MCP client and server and enforces a policy on what the server sends
+// With a command it proxies stdio; with -listen and -upstream, Streamable HTTP
+func runProxy(args []string) error {
+	fs := flag.NewFlagSet("proxy", flag.ExitOnError)
+	policyPath := fs.String("policy", "", "policy file (default: strip poisoned tools, annotate warnings, block mutations)")
+	logPath := fs.String("log", "", "file to append decisions to as JSON lines (default: stderr)")
+	listen := fs.String("listen", "", "address to serve Streamable HTTP on, e.g. 127.0.0.1:8931")
+	upstream := fs.String("upstream", "", "MCP endpoint URL of the upstream server, with -listen")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mcpsek proxy [-policy FILE] [-log FILE] -- COMMAND [ARGS...]")
+		fmt.Fprintln(fs.Output(), "       mcpsek proxy [-policy FILE] [-log FILE] -listen ADDR -upstream URL")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	httpMode := *listen != "" || *upstream != ""
+	if httpMode && (*listen == "" || *upstream == "" || fs.NArg() > 0) || !httpMode && fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	policy, err := proxy.LoadPolicy(*policyPath)
+	if err != nil {
+		return err
+	}
+
+	var decisions io.Writer = os.Stderr
+	if *logPath != "" {
+		f, err := os.OpenFile(*logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("open decision log: %w", err)
+		}
+		defer f.Close()
+		decisions = f
+	}
+
+	// The classifier is optional, as it is for scans
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	var model *classifier.Model
+	if cfg.ClassifierModel != "" {
+		if model, err = classifier.Load(cfg.ClassifierModel); err != nil {
+			log.Printf("Injection classifier disabled: %v", err)
+		}
+	}
+
+	gateway := proxy.New(policy, model, cfg.ClassifierThreshold, proxy.NewLogger(decisions))
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if !httpMode {
+		// stdout carries the protocol, so everything else goes to stderr
+		return gateway.RunStdio(ctx, fs.Args(), os.Stdin, os.Stdout)
+	}
+
+	server := &http.Server{Addr: *listen, Handler: gateway.HTTPHandler(*upstream)}
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+
+	log.Printf("mcpsek proxy listening on %s, upstream %s", *listen, *upstream)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("serve: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/mcpsek/mcpsek/internal/config"
+	"github.com/mcpsek/mcpsek/internal/database"
+	"github.com/mcpsek/mcpsek/internal/scanner"
+)
+
+// runScan scans one server on demand, optionally at a specific tag, commit or published version
+func runScan(args []string) error {
+	fs := flag.NewFlagSet("scan", flag.ExitOnError)
+	ref := fs.String("ref", "", "tag, branch or commit to scan instead of the default branch")
+	version := fs.String("version", "", "published package version to scan, found by its release tag")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mcpsek scan [-ref REF | -version VERSION] SERVER_ID")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || (*ref != "" && *version != "") {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	id, err := uuid.Parse(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid server ID: %w", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	if err := os.MkdirAll(cfg.CloneDir, 0755); err != nil {
+		return fmt.Errorf("create clone directory: %w", err)
+	}
+
+	ctx := context.Background()
+	db, err := database.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return fmt.Errorf("connect to database: %w", err)
+	}
+	defer db.Close()
+
+	server, err := db.GetServer(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	scn := newScanner(cfg, db)
+
+	var result *scanner.ScanResult
+	switch {
+	case *ref != "":
+		result, err = scn.ScanRef(ctx, server.ID, server.SourceURL, *ref)
+	case *version != "":
+		packageName := ""
+		if server.PackageName != nil {
+			packageName = *server.PackageName
+		}
+		result, err = scn.ScanRef(ctx, server.ID, server.SourceURL, scanner.VersionRefs(*version, packageName)...)
+	default:
+		result, err = scn.Scan(ctx, server.ID, server.SourceURL)
+	}
+	if err != nil {
+		return err
+	}
+
+	rev := result.Revision
+	log.Printf("Scanned %s at %s (ref: %s, version: %s)", server.Name, rev.CommitSHA,
+		orNone(rev.Ref), orNone(rev.PackageVersion))
+	log.Printf("Trust score %d: integrity %s, auth %s, exposure %s", result.TrustScore,
+		result.IntegrityResult.Status, result.AuthResult.Status, result.ExposureResult.Status)
+	return nil
+}
+
+// orNone returns s, or "none" when it's empty
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/mcpsek/mcpsek/internal/classifier"
+)
+
+// runTrain builds a prompt-injection classifier model from a labelled JSONL corpus
+func runTrain(args []string) error {
+	opts := classifier.DefaultTrainOptions
+
+	fs := flag.NewFlagSet("train", flag.ExitOnError)
+	corpus := fs.String("corpus", "models/injection_corpus.jsonl", "labelled JSONL corpus of {\"text\", \"label\"} lines")
+	out := fs.String("out", "models/injection.json", "model file to write")
+	fs.IntVar(&opts.Epochs, "epochs", opts.Epochs, "passes over the corpus")
+	fs.Float64Var(&opts.LearningRate, "learning-rate", opts.LearningRate, "gradient descent step size")
+	fs.Float64Var(&opts.L2, "l2", opts.L2, "regularization strength")
+	fs.IntVar(&opts.MinCount, "min-count", opts.MinCount, "examples an n-gram must appear in to become a feature")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	examples, err := classifier.ReadCorpus(*corpus)
+	if err != nil {
+		return err
+	}
+
+	model, err := classifier.Train(examples, opts)
+	if err != nil {
+		return fmt.Errorf("train: %w", err)
+	}
+
+	if err := model.Save(*out); err != nil {
+		return err
+	}
+
+	log.Printf("Trained on %d examples: %d features, %.1f%% training accuracy",
+		len(examples), len(model.Weights), 100*model.Accuracy(examples, 0.5))
+	log.Printf("Model written to %s", *out)
+	return nil
+}
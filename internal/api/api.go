@@ -1,24 +1,31 @@
 package api
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/google/uuid"
 	"github.com/mcpsek/mcpsek/internal/database"
+	"github.com/mcpsek/mcpsek/internal/scanner"
+	"github.com/mcpsek/mcpsek/internal/similarity"
 )
 
 // API handles HTTP API requests
 type API struct {
-	db *database.DB
+	db         *database.DB
+	adminToken string // Required for review actions; empty disables them
 }
 
 // New creates a new API handler
-func New(db *database.DB) *API {
-	return &API{db: db}
+func New(db *database.DB, adminToken string) *API {
+	return &API{db: db, adminToken: adminToken}
 }
 
 // Router creates the API router
@@ -35,10 +42,21 @@ func (a *API) Router() http.Handler {
 	r.Get("/servers/{id}", a.getServer)
 	r.Get("/servers/{id}/scans", a.getServerScans)
 	r.Get("/servers/{id}/mutations", a.getServerMutations)
+	r.Get("/servers/{id}/versions", a.getServerVersions)
+	r.Get("/servers/{id}/tools", a.getServerTools)
+	r.Get("/servers/{id}/findings", a.getServerFindings)
+	r.Get("/servers/{id}/findings/compare", a.compareServerFindings)
 	r.Get("/search", a.searchServers)
 	r.Get("/stats", a.getStats)
 	r.Get("/recent/critical", a.getRecentCritical)
 	r.Get("/recent/mutations", a.getRecentMutations)
+	r.Get("/mutations/{id}/diff", a.getMutationDiff)
+	r.Get("/tools/collisions", a.getToolCollisions)
+	r.Get("/tools/{id}/similar", a.getSimilarTools)
+	r.Get("/servers/{id}/similarity-flags", a.getSimilarityFlags)
+	r.Post("/similarity-flags/{id}/review", a.reviewSimilarityFlag)
+	r.Post("/verify", a.verify)
+	r.Post("/verify/batch", a.verifyBatch)
 
 	return r
 }
@@ -164,6 +182,122 @@ func (a *API) getServerMutations(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// getServerFindings handles GET /servers/{id}/findings?state=open|fixed
+func (a *API) getServerFindings(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid_id", "Invalid server ID")
+		return
+	}
+
+	state := r.URL.Query().Get("state")
+	if state != "" && state != database.FindingOpen && state != database.FindingFixed {
+		respondError(w, http.StatusBadRequest, "invalid_state", "state must be 'open' or 'fixed'")
+		return
+	}
+
+	page, perPage := parsePagination(r)
+
+	findings, total, err := a.db.GetFindingsForServer(r.Context(), id, state, perPage, (page-1)*perPage)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "database_error", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, Response{
+		Data: findings,
+		Meta: &Meta{
+			Total:   total,
+			Page:    page,
+			PerPage: perPage,
+		},
+	})
+}
+
+// compareServerFindings handles GET /servers/{id}/findings/compare?from=<scan id>&to=<scan id>
+// Without to, compares the latest scan with recorded findings; without from, the scan before to
+func (a *API) compareServerFindings(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid_id", "Invalid server ID")
+		return
+	}
+
+	var scanIDs [2]*uuid.UUID
+	for i, param := range []string{"from", "to"} {
+		value := r.URL.Query().Get(param)
+		if value == "" {
+			continue
+		}
+		scanID, err := uuid.Parse(value)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid_scan", "Invalid "+param+" scan ID")
+			return
+		}
+		scanIDs[i] = &scanID
+	}
+
+	fromScan, toScan, err := a.db.GetFindingsScans(r.Context(), id, scanIDs[0], scanIDs[1])
+	if errors.Is(err, database.ErrNotFound) {
+		respondError(w, http.StatusNotFound, "not_found", "Scan not found or has no recorded findings")
+		return
+	} else if err != nil {
+		respondError(w, http.StatusInternalServerError, "database_error", err.Error())
+		return
+	}
+	for _, scan := range []*database.Scan{fromScan, toScan} {
+		if scan != nil && (scan.ServerID != id || !scan.FindingsRecorded) {
+			respondError(w, http.StatusNotFound, "not_found", "Scan not found or has no recorded findings")
+			return
+		}
+	}
+
+	after, err := a.db.GetFindingsForScan(r.Context(), toScan.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "database_error", err.Error())
+		return
+	}
+	before := make([]*database.Finding, 0)
+	if fromScan != nil {
+		before, err = a.db.GetFindingsForScan(r.Context(), fromScan.ID)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "database_error", err.Error())
+			return
+		}
+	}
+
+	beforeIDs := make(map[uuid.UUID]bool)
+	for _, f := range before {
+		beforeIDs[f.ID] = true
+	}
+	afterIDs := make(map[uuid.UUID]bool)
+	newFindings := make([]*database.Finding, 0)
+	for _, f := range after {
+		afterIDs[f.ID] = true
+		if !beforeIDs[f.ID] {
+			newFindings = append(newFindings, f)
+		}
+	}
+	resolved := make([]*database.Finding, 0)
+	for _, f := range before {
+		if !afterIDs[f.ID] {
+			resolved = append(resolved, f)
+		}
+	}
+
+	data := map[string]interface{}{
+		"from":       fromScan,
+		"to":         toScan,
+		"new":        newFindings,
+		"resolved":   resolved,
+		"persistent": len(after) - len(newFindings),
+	}
+
+	respondJSON(w, http.StatusOK, Response{Data: data})
+}
+
 // searchServers handles GET /search?q=query
 func (a *API) searchServers(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
@@ -201,6 +335,92 @@ func (a *API) getStats(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, Response{Data: stats})
 }
 
+// getServerVersions handles GET /servers/{id}/versions
+func (a *API) getServerVersions(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid_id", "Invalid server ID")
+		return
+	}
+
+	versions, err := a.db.GetServerVersions(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "database_error", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, Response{Data: versions})
+}
+
+// getServerTools handles GET /servers/{id}/tools?scan=<scan id>|at=<RFC 3339 time or YYYY-MM-DD>
+// Without either, returns the tools of the latest default-branch scan
+func (a *API) getServerTools(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid_id", "Invalid server ID")
+		return
+	}
+
+	var scan *database.Scan
+	if scanStr := r.URL.Query().Get("scan"); scanStr != "" {
+		scanID, err := uuid.Parse(scanStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid_scan", "Invalid scan ID")
+			return
+		}
+		scan, err = a.db.GetScan(r.Context(), scanID)
+		if err != nil || scan.ServerID != id {
+			respondError(w, http.StatusNotFound, "not_found", "Scan not found")
+			return
+		}
+		if !scan.ToolsRecorded {
+			respondError(w, http.StatusNotFound, "no_snapshot", "Scan predates tool snapshots")
+			return
+		}
+	} else {
+		at := time.Now()
+		if atStr := r.URL.Query().Get("at"); atStr != "" {
+			at, err = parseAsOf(atStr)
+			if err != nil {
+				respondError(w, http.StatusBadRequest, "invalid_time", "at must be an RFC 3339 time or a YYYY-MM-DD date")
+				return
+			}
+		}
+		scan, err = a.db.GetScanAsOf(r.Context(), id, at)
+		if err != nil {
+			respondError(w, http.StatusNotFound, "not_found", err.Error())
+			return
+		}
+	}
+
+	tools, err := a.db.GetToolsForScan(r.Context(), scan.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "database_error", err.Error())
+		return
+	}
+
+	data := map[string]interface{}{
+		"scan":  scan,
+		"tools": tools,
+	}
+
+	respondJSON(w, http.StatusOK, Response{Data: data})
+}
+
+// parseAsOf parses a point in time; a bare date means the end of that day in UTC
+func parseAsOf(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	day, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return day.Add(24*time.Hour - time.Nanosecond), nil
+}
+
 // getRecentCritical handles GET /recent/critical
 func (a *API) getRecentCritical(w http.ResponseWriter, r *http.Request) {
 	scans, err := a.db.GetRecentCriticalScans(r.Context(), 10)
@@ -223,6 +443,137 @@ func (a *API) getRecentMutations(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, Response{Data: mutations})
 }
 
+// getMutationDiff handles GET /mutations/{id}/diff
+func (a *API) getMutationDiff(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid_id", "Invalid mutation ID")
+		return
+	}
+
+	mutation, err := a.db.GetMutation(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "not_found", "Mutation not found")
+		return
+	}
+
+	// Mutations detected before diffs were stored get one computed now, without the classifier
+	diff := mutation.Diff
+	if diff == nil {
+		diff, _ = json.Marshal(scanner.DiffMutation(mutation))
+	}
+
+	data := map[string]interface{}{
+		"mutation": mutation,
+		"diff":     diff,
+	}
+
+	respondJSON(w, http.StatusOK, Response{Data: data})
+}
+
+// getToolCollisions handles GET /tools/collisions?kind=name_collision|cross_reference
+func (a *API) getToolCollisions(w http.ResponseWriter, r *http.Request) {
+	index, err := a.db.GetToolIndex(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "database_error", err.Error())
+		return
+	}
+
+	kind := r.URL.Query().Get("kind")
+	collisions := make([]scanner.ToolCollision, 0)
+	for _, c := range scanner.FindCollisions(index) {
+		if kind == "" || c.Kind == kind {
+			collisions = append(collisions, c)
+		}
+	}
+
+	page, perPage := parsePagination(r)
+	start := min((page-1)*perPage, len(collisions))
+	end := min(start+perPage, len(collisions))
+
+	respondJSON(w, http.StatusOK, Response{
+		Data: collisions[start:end],
+		Meta: &Meta{
+			Total:   len(collisions),
+			Page:    page,
+			PerPage: perPage,
+		},
+	})
+}
+
+// getSimilarTools handles GET /tools/{id}/similar?threshold=0.5
+func (a *API) getSimilarTools(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid_id", "Invalid tool ID")
+		return
+	}
+
+	threshold := similarity.DefaultThreshold
+	if t, err := strconv.ParseFloat(r.URL.Query().Get("threshold"), 64); err == nil && t > 0 && t <= 1 {
+		threshold = t
+	}
+
+	tools, err := a.db.GetSimilarTools(r.Context(), id, threshold)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "not_found", "Tool not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, Response{Data: tools})
+}
+
+// getSimilarityFlags handles GET /servers/{id}/similarity-flags
+func (a *API) getSimilarityFlags(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid_id", "Invalid server ID")
+		return
+	}
+
+	flags, err := a.db.GetSimilarityFlagsForServer(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "database_error", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, Response{Data: flags})
+}
+
+// reviewSimilarityFlag handles POST /similarity-flags/{id}/review
+func (a *API) reviewSimilarityFlag(w http.ResponseWriter, r *http.Request) {
+	if !a.authorized(r) {
+		respondError(w, http.StatusForbidden, "forbidden", "Review actions need the admin token")
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid_id", "Invalid flag ID")
+		return
+	}
+
+	if err := a.db.ReviewSimilarityFlag(r.Context(), id); errors.Is(err, database.ErrNotFound) {
+		respondError(w, http.StatusNotFound, "not_found", "Unreviewed flag not found")
+		return
+	} else if err != nil {
+		respondError(w, http.StatusInternalServerError, "database_error", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, Response{Data: map[string]string{"id": id.String(), "status": "reviewed"}})
+}
+
+// authorized reports whether a request carries the admin token; always false when none is set
+func (a *API) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return a.adminToken != "" && ok && subtle.ConstantTimeCompare([]byte(token), []byte(a.adminToken)) == 1
+}
+
 // parsePagination extracts pagination parameters from request
 func parsePagination(r *http.Request) (page, perPage int) {
 	page, _ = strconv.Atoi(r.URL.Query().Get("page"))
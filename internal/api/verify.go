@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mcpsek/mcpsek/internal/scanner"
+)
+
+// Request body limits for verification
+const (
+	maxVerifyBody      = 1 << 20
+	maxVerifyBatchBody = 8 << 20
+	maxVerifyBatch     = 100
+)
+
+// Verification verdicts
+const (
+	verdictVerified      = "verified"       // Every observed tool matches the latest scan and none are missing
+	verdictMismatch      = "mismatch"       // Some tool is mutated, unknown or missing
+	verdictUnverified    = "unverified"     // No tools/list payload was given; trust score only
+	verdictNotScanned    = "not_scanned"    // The server has no scan with recorded tools
+	verdictUnknownServer = "unknown_server" // No server matches the identifier
+)
+
+// verifyRequest is the body of POST /verify
+type verifyRequest struct {
+	Server   string          `json:"server"`   // Server ID, package name, source URL or name
+	Registry string          `json:"registry"` // Optional: "npm" or "pypi", narrows package name matches
+	Tools    json.RawMessage `json:"tools"`    // The tools/list result, its tools array or the JSON-RPC response
+}
+
+// verifyBatchRequest is the body of POST /verify/batch: either a list of servers, or a client config
+// with the tools/list payload of each of its servers keyed by the config's server name
+type verifyBatchRequest struct {
+	Servers []verifyRequest            `json:"servers"`
+	Config  json.RawMessage            `json:"config"`
+	Tools   map[string]json.RawMessage `json:"tools"`
+}
+
+// verifyResult is the verdict for one server
+type verifyResult struct {
+	Server       string                     `json:"server"` // Identifier as given, or the client config's server name
+	Verdict      string                     `json:"verdict"`
+	ServerID     *uuid.UUID                 `json:"server_id,omitempty"`
+	ServerName   string                     `json:"server_name,omitempty"`
+	TrustScore   *int                       `json:"trust_score,omitempty"`
+	LastScanned  *time.Time                 `json:"last_scanned,omitempty"`
+	MutationRisk *string                    `json:"mutation_risk,omitempty"` // "runtime" means hashes can drift without a release
+	ScanID       *uuid.UUID                 `json:"scan_id,omitempty"`       // Scan the tools were compared with
+	Tools        []scanner.ToolVerification `json:"tools,omitempty"`
+	Missing      []string                   `json:"missing,omitempty"` // Scanned tools the client didn't see
+	Error        string                     `json:"error,omitempty"`
+}
+
+// verify handles POST /verify
+func (a *API) verify(w http.ResponseWriter, r *http.Request) {
+	var req verifyRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxVerifyBody)).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid_body", "Body must be JSON with server and tools")
+		return
+	}
+	if req.Server == "" {
+		respondError(w, http.StatusBadRequest, "missing_server", "server is required")
+		return
+	}
+
+	result := a.verifyServer(r.Context(), req.Server, req.Server, req.Registry, req.Tools)
+	if result.Verdict == verdictUnknownServer {
+		respondJSON(w, http.StatusNotFound, Response{Data: result})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, Response{Data: result})
+}
+
+// verifyBatch handles POST /verify/batch
+func (a *API) verifyBatch(w http.ResponseWriter, r *http.Request) {
+	var req verifyBatchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxVerifyBatchBody)).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid_body", "Body must be JSON with servers or config")
+		return
+	}
+
+	results := make([]verifyResult, 0)
+	if len(req.Config) > 0 {
+		servers, err := scanner.ParseClientConfig(req.Config)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid_config", err.Error())
+			return
+		}
+		if len(servers) > maxVerifyBatch {
+			respondError(w, http.StatusBadRequest, "too_many_servers", "At most 100 servers per batch")
+			return
+		}
+		for _, server := range servers {
+			// Servers not launched from a registry package can only be found by name
+			identifier := server.Package
+			if identifier == "" {
+				identifier = server.Name
+			}
+			results = append(results, a.verifyServer(r.Context(), server.Name, identifier, server.Registry, req.Tools[server.Name]))
+		}
+	} else {
+		if len(req.Servers) > maxVerifyBatch {
+			respondError(w, http.StatusBadRequest, "too_many_servers", "At most 100 servers per batch")
+			return
+		}
+		for _, server := range req.Servers {
+			results = append(results, a.verifyServer(r.Context(), server.Server, server.Server, server.Registry, server.Tools))
+		}
+	}
+
+	respondJSON(w, http.StatusOK, Response{Data: results})
+}
+
+// verifyServer compares the tools a client observed with the latest scan of the server identifier names
+func (a *API) verifyServer(ctx context.Context, label, identifier, registry string, toolsList json.RawMessage) verifyResult {
+	result := verifyResult{Server: label}
+
+	server, err := a.db.FindServer(ctx, identifier, registry)
+	if err != nil {
+		result.Verdict = verdictUnknownServer
+		return result
+	}
+	result.ServerID = &server.ID
+	result.ServerName = server.Name
+	result.TrustScore = &server.TrustScore
+	result.LastScanned = server.LastScanned
+	result.MutationRisk = server.MutationRisk
+
+	if len(toolsList) == 0 || string(toolsList) == "null" {
+		result.Verdict = verdictUnverified
+		return result
+	}
+	observed, err := scanner.ParseToolsList(toolsList)
+	if err != nil {
+		result.Verdict = verdictUnverified
+		result.Error = err.Error()
+		return result
+	}
+
+	scan, err := a.db.GetScanAsOf(ctx, server.ID, time.Now())
+	if err != nil {
+		result.Verdict = verdictNotScanned
+		return result
+	}
+	result.ScanID = &scan.ID
+
+	current, err := a.db.GetToolsForScan(ctx, scan.ID)
+	if err != nil {
+		result.Verdict = verdictNotScanned
+		result.Error = err.Error()
+		return result
+	}
+	// Older definitions only date mutated tools, so failing to read them isn't fatal
+	known, _ := a.db.GetKnownToolDefinitions(ctx, server.ID)
+
+	result.Tools, result.Missing = scanner.VerifyTools(observed, current, known)
+	result.Verdict = verdictVerified
+	if len(result.Missing) > 0 {
+		result.Verdict = verdictMismatch
+	}
+	for _, tool := range result.Tools {
+		if tool.Status != scanner.VerifyMatched {
+			result.Verdict = verdictMismatch
+		}
+	}
+
+	return result
+}
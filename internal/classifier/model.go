@@ -0,0 +1,95 @@
+package classifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// ModelVersion is the model file format this package reads and writes
+const ModelVersion = 1
+
+// Model is a logistic regression prompt-injection classifier over character n-grams
+type Model struct {
+	Version  int                `json:"version"`
+	NgramMin int                `json:"ngram_min"`
+	NgramMax int                `json:"ngram_max"`
+	Bias     float64            `json:"bias"`
+	Weights  map[string]float64 `json:"weights"`
+}
+
+// Load reads a model file
+func Load(path string) (*Model, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read model: %w", err)
+	}
+
+	var m Model
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse model: %w", err)
+	}
+	if m.Version != ModelVersion {
+		return nil, fmt.Errorf("unsupported model version %d", m.Version)
+	}
+	if m.NgramMin < 1 || m.NgramMax < m.NgramMin {
+		return nil, fmt.Errorf("invalid n-gram range %d-%d", m.NgramMin, m.NgramMax)
+	}
+
+	return &m, nil
+}
+
+// Save writes the model file
+func (m *Model) Save(path string) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("encode model: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write model: %w", err)
+	}
+	return nil
+}
+
+// Probability returns how likely text is to be a prompt injection, from 0 to 1
+func (m *Model) Probability(text string) float64 {
+	z := m.Bias
+	for ngram, value := range Features(text, m.NgramMin, m.NgramMax) {
+		z += m.Weights[ngram] * value
+	}
+	return sigmoid(z)
+}
+
+// Features returns the L2-normalized presence of each character n-gram in text,
+// so long descriptions don't score higher just for being long
+func Features(text string, ngramMin, ngramMax int) map[string]float64 {
+	runes := []rune(" " + normalize(text) + " ")
+
+	features := make(map[string]float64)
+	for n := ngramMin; n <= ngramMax; n++ {
+		for i := 0; i+n <= len(runes); i++ {
+			features[string(runes[i:i+n])] = 1
+		}
+	}
+
+	if len(features) > 0 {
+		norm := 1 / math.Sqrt(float64(len(features)))
+		for ngram := range features {
+			features[ngram] = norm
+		}
+	}
+	return features
+}
+
+// normalize lowercases text and collapses whitespace; punctuation is kept since
+// tags like <IMPORTANT> are part of the signal
+func normalize(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// sigmoid maps a log-odds score to a probability
+func sigmoid(z float64) float64 {
+	return 1 / (1 + math.Exp(-z))
+}
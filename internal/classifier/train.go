@@ -0,0 +1,172 @@
+package classifier
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Example is one labelled description in a training corpus
+type Example struct {
+	Text  string `json:"text"`
+	Label int    `json:"label"` // 1 for prompt injection, 0 for benign
+}
+
+// TrainOptions configures training
+type TrainOptions struct {
+	NgramMin     int
+	NgramMax     int
+	Epochs       int
+	LearningRate float64
+	L2           float64 // Regularization strength
+	MinCount     int     // Examples an n-gram must appear in to become a feature
+}
+
+// DefaultTrainOptions are the options the shipped model is trained with
+var DefaultTrainOptions = TrainOptions{
+	NgramMin:     3,
+	NgramMax:     5,
+	Epochs:       30,
+	LearningRate: 0.5,
+	L2:           1e-4,
+	MinCount:     2,
+}
+
+// minWeight is the smallest weight kept in a saved model
+const minWeight = 1e-3
+
+// ReadCorpus reads a JSONL corpus of {"text": ..., "label": 0|1} lines
+func ReadCorpus(path string) ([]Example, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open corpus: %w", err)
+	}
+	defer file.Close()
+
+	examples := make([]Example, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var ex Example
+		if err := json.Unmarshal([]byte(line), &ex); err != nil {
+			return nil, fmt.Errorf("corpus line %d: %w", lineNum, err)
+		}
+		if ex.Label != 0 && ex.Label != 1 {
+			return nil, fmt.Errorf("corpus line %d: label must be 0 or 1", lineNum)
+		}
+		examples = append(examples, ex)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read corpus: %w", err)
+	}
+
+	return examples, nil
+}
+
+// Train fits a model to the examples with stochastic gradient descent; a fixed seed
+// keeps the model file reproducible for a given corpus
+func Train(examples []Example, opts TrainOptions) (*Model, error) {
+	positives := 0
+	for _, ex := range examples {
+		positives += ex.Label
+	}
+	if positives == 0 || positives == len(examples) {
+		return nil, fmt.Errorf("corpus needs both injection and benign examples")
+	}
+
+	// Vocabulary: n-grams seen in at least MinCount examples
+	vectors := make([][]feature, len(examples))
+	counts := make(map[string]int)
+	for i, ex := range examples {
+		vectors[i] = sortedFeatures(Features(ex.Text, opts.NgramMin, opts.NgramMax))
+		for _, f := range vectors[i] {
+			counts[f.ngram]++
+		}
+	}
+
+	weights := make(map[string]float64)
+	for ngram, count := range counts {
+		if count >= opts.MinCount {
+			weights[ngram] = 0
+		}
+	}
+
+	bias := math.Log(float64(positives) / float64(len(examples)-positives))
+	rng := rand.New(rand.NewSource(1))
+	order := rng.Perm(len(examples))
+
+	for epoch := 0; epoch < opts.Epochs; epoch++ {
+		rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+		for _, i := range order {
+			z := bias
+			for _, f := range vectors[i] {
+				z += weights[f.ngram] * f.value
+			}
+			gradient := sigmoid(z) - float64(examples[i].Label)
+
+			bias -= opts.LearningRate * gradient
+			for _, f := range vectors[i] {
+				if w, ok := weights[f.ngram]; ok {
+					weights[f.ngram] = w - opts.LearningRate*(gradient*f.value+opts.L2*w)
+				}
+			}
+		}
+	}
+
+	for ngram, w := range weights {
+		if math.Abs(w) < minWeight {
+			delete(weights, ngram)
+		}
+	}
+
+	return &Model{
+		Version:  ModelVersion,
+		NgramMin: opts.NgramMin,
+		NgramMax: opts.NgramMax,
+		Bias:     bias,
+		Weights:  weights,
+	}, nil
+}
+
+// feature is one n-gram and its value
+type feature struct {
+	ngram string
+	value float64
+}
+
+// sortedFeatures orders features by n-gram, so sums don't depend on map iteration order
+func sortedFeatures(features map[string]float64) []feature {
+	sorted := make([]feature, 0, len(features))
+	for ngram, value := range features {
+		sorted = append(sorted, feature{ngram, value})
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ngram < sorted[j].ngram })
+	return sorted
+}
+
+// Accuracy returns the share of examples the model labels correctly at threshold
+func (m *Model) Accuracy(examples []Example, threshold float64) float64 {
+	if len(examples) == 0 {
+		return 0
+	}
+	correct := 0
+	for _, ex := range examples {
+		predicted := 0
+		if m.Probability(ex.Text) >= threshold {
+			predicted = 1
+		}
+		if predicted == ex.Label {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(examples))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -16,19 +17,39 @@ type Config struct {
 	HTTPAddr string
 
 	// Scanner
-	CloneDir      string
-	ScanWorkers   int
-	ScanInterval  time.Duration
+	CloneDir     string
+	ScanWorkers  int
+	ScanInterval time.Duration
 
 	// Discovery
 	DiscoveryInterval time.Duration
 	GitHubToken       string
 
 	// API
-	APIRateLimit int // requests per minute
+	APIRateLimit int    // requests per minute
+	AdminToken   string // Bearer token for review actions; empty disables them
 
 	// Shodan (optional)
-	ShodanAPIKey string
+	ShodanAPIKey    string
+	ShodanBaseURL   string        // Shodan API or a compatible stand-in
+	ShodanRateLimit int           // requests per minute
+	ShodanCacheTTL  time.Duration // How long lookup responses are reused
+
+	// Finding context
+	ContextWeights map[string]float64 // Confidence per file class, e.g. "test=0.3,example=0.4"
+	MinConfidence  float64            // Findings below this confidence don't affect status
+
+	// Reachability
+	ScoreUnreachable bool // Count findings in code not reachable from the package entrypoints
+
+	// Git history scanning
+	DeepScan          bool  // Fetch full history and scan every commit for secrets
+	HistoryMaxCommits int   // Most recent commits to scan
+	HistoryMaxBytes   int64 // Cap on diff output read per repository
+
+	// Prompt-injection classifier
+	ClassifierModel     string  // Model file built by `mcpsek train`; empty disables the classifier
+	ClassifierThreshold float64 // Injection probability that raises a warning
 }
 
 // Load reads configuration from environment variables
@@ -42,13 +63,29 @@ func Load() (*Config, error) {
 		DiscoveryInterval: getEnvDuration("MCPSEK_DISCOVERY_INTERVAL", "168h"), // 7 days
 		GitHubToken:       getEnv("MCPSEK_GITHUB_TOKEN", ""),
 		APIRateLimit:      getEnvInt("MCPSEK_API_RATE_LIMIT", 100),
+		AdminToken:        getEnv("MCPSEK_ADMIN_TOKEN", ""),
 		ShodanAPIKey:      getEnv("MCPSEK_SHODAN_API_KEY", ""),
+		ShodanBaseURL:     getEnv("MCPSEK_SHODAN_BASE_URL", "https://api.shodan.io"),
+		ShodanRateLimit:   getEnvInt("MCPSEK_SHODAN_RATE_LIMIT", 60),
+		ShodanCacheTTL:    getEnvDuration("MCPSEK_SHODAN_CACHE_TTL", "24h"),
+		ContextWeights:    getEnvFloatMap("MCPSEK_CONTEXT_WEIGHTS"),
+		MinConfidence:     getEnvFloat("MCPSEK_MIN_CONFIDENCE", 0.5),
+		ScoreUnreachable:  getEnvBool("MCPSEK_SCORE_UNREACHABLE", false),
+		DeepScan:          getEnvBool("MCPSEK_DEEP_SCAN", false),
+		HistoryMaxCommits: getEnvInt("MCPSEK_HISTORY_MAX_COMMITS", 1000),
+		HistoryMaxBytes:   int64(getEnvInt("MCPSEK_HISTORY_MAX_BYTES", 100*1024*1024)),
+
+		ClassifierModel:     getEnv("MCPSEK_CLASSIFIER_MODEL", "models/injection.json"),
+		ClassifierThreshold: getEnvFloat("MCPSEK_CLASSIFIER_THRESHOLD", 0.6),
 	}
 
 	// Validate required fields
 	if cfg.DatabaseURL == "" {
 		return nil, fmt.Errorf("MCPSEK_DB_URL is required")
 	}
+	if cfg.MinConfidence < 0 || cfg.MinConfidence > 1 {
+		return nil, fmt.Errorf("MCPSEK_MIN_CONFIDENCE must be between 0 and 1")
+	}
 
 	return cfg, nil
 }
@@ -81,3 +118,39 @@ func getEnvDuration(key string, defaultValue string) time.Duration {
 	}
 	return duration
 }
+
+// getEnvBool retrieves an environment variable as a boolean or returns a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvFloat retrieves an environment variable as a float or returns a default value
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvFloatMap parses an environment variable of the form "a=0.1,b=0.2"
+// Malformed entries are skipped
+func getEnvFloatMap(key string) map[string]float64 {
+	result := make(map[string]float64)
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if floatValue, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64); err == nil {
+			result[strings.TrimSpace(parts[0])] = floatValue
+		}
+	}
+	return result
+}
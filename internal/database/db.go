@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -9,6 +10,9 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrNotFound is wrapped by lookups that callers answer with a 404 rather than a server error
+var ErrNotFound = errors.New("not found")
+
 // DB represents the database connection pool
 type DB struct {
 	pool *pgxpool.Pool
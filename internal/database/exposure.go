@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ObservedExposure is live internet exposure seen by an external scanner,
+// kept separate from the static exposure analysis of the source
+type ObservedExposure struct {
+	Source       string             `json:"source"` // "shodan"
+	CheckedAt    time.Time          `json:"checked_at"`
+	Queries      []string           `json:"queries"`
+	TotalMatches int                `json:"total_matches"` // Hosts matching the MCP signature queries
+	Instances    []ObservedInstance `json:"instances"`     // Hosts linked to this server
+}
+
+// ObservedInstance is one internet-exposed host linked to a server
+type ObservedInstance struct {
+	IP        string    `json:"ip"`
+	Port      int       `json:"port"`
+	Hostnames []string  `json:"hostnames,omitempty"`
+	Org       string    `json:"org,omitempty"`
+	Country   string    `json:"country,omitempty"`
+	TLS       bool      `json:"tls"`
+	Signature string    `json:"signature"` // MCP signature the host matched
+	LinkedBy  string    `json:"linked_by"` // Server identifier found in the banner
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// UpdateObservedExposure stores the observed exposure for a server
+func (db *DB) UpdateObservedExposure(ctx context.Context, id uuid.UUID, observed *ObservedExposure) error {
+	query := `
+		UPDATE servers
+		SET observed_exposure = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+	err := db.Exec(ctx, query, observed, id)
+	if err != nil {
+		return fmt.Errorf("update observed exposure: %w", err)
+	}
+	return nil
+}
+
+// GetExposureCache returns a cached lookup response younger than maxAge
+func (db *DB) GetExposureCache(ctx context.Context, source, query string, maxAge time.Duration) (json.RawMessage, bool, error) {
+	sql := `
+		SELECT response FROM exposure_cache
+		WHERE source = $1 AND query = $2 AND fetched_at > $3
+	`
+
+	var response json.RawMessage
+	err := db.pool.QueryRow(ctx, sql, source, query, time.Now().Add(-maxAge)).Scan(&response)
+	if err == pgx.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("get exposure cache: %w", err)
+	}
+
+	return response, true, nil
+}
+
+// PutExposureCache stores a lookup response
+func (db *DB) PutExposureCache(ctx context.Context, source, query string, response json.RawMessage) error {
+	sql := `
+		INSERT INTO exposure_cache (source, query, response, fetched_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (source, query)
+		DO UPDATE SET response = EXCLUDED.response, fetched_at = NOW()
+	`
+	err := db.Exec(ctx, sql, source, query, response)
+	if err != nil {
+		return fmt.Errorf("put exposure cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Finding is an issue tracked across a server's scans by its fingerprint
+type Finding struct {
+	ID              uuid.UUID  `json:"id"`
+	ServerID        uuid.UUID  `json:"server_id"`
+	ServerName      string     `json:"server_name"`
+	Fingerprint     string     `json:"fingerprint"`
+	Check           string     `json:"check"` // "tool_integrity", "auth" or "exposure"
+	Rule            string     `json:"rule"`
+	Severity        string     `json:"severity"` // "warning" or "critical"
+	ToolName        *string    `json:"tool_name,omitempty"`
+	FilePath        *string    `json:"file_path,omitempty"`
+	Line            *int       `json:"line,omitempty"`
+	Message         string     `json:"message"`
+	FirstSeen       time.Time  `json:"first_seen"`
+	LastSeen        time.Time  `json:"last_seen"`
+	OpenedAt        time.Time  `json:"opened_at"` // FirstSeen, or when a fixed finding came back
+	FixedAt         *time.Time `json:"fixed_at,omitempty"`
+	FirstSeenScanID *uuid.UUID `json:"first_seen_scan_id,omitempty"`
+	LastSeenScanID  *uuid.UUID `json:"last_seen_scan_id,omitempty"`
+	FixedScanID     *uuid.UUID `json:"fixed_scan_id,omitempty"`
+}
+
+// Finding states for filtering
+const (
+	FindingOpen  = "open"
+	FindingFixed = "fixed"
+)
+
+// findingColumns lists the findings columns in the order scanFindingRows expects
+const findingColumns = `f.id, f.server_id, s.name, f.fingerprint, f.check_name, f.rule, f.severity,
+			   f.tool_name, f.file_path, f.line, f.message, f.first_seen, f.last_seen, f.opened_at, f.fixed_at,
+			   f.first_seen_scan_id, f.last_seen_scan_id, f.fixed_scan_id`
+
+// scanFindingRows reads the findings selected with findingColumns
+func scanFindingRows(rows pgx.Rows) ([]*Finding, error) {
+	defer rows.Close()
+
+	findings := make([]*Finding, 0)
+	for rows.Next() {
+		f := &Finding{}
+		err := rows.Scan(
+			&f.ID, &f.ServerID, &f.ServerName, &f.Fingerprint, &f.Check, &f.Rule, &f.Severity,
+			&f.ToolName, &f.FilePath, &f.Line, &f.Message, &f.FirstSeen, &f.LastSeen, &f.OpenedAt, &f.FixedAt,
+			&f.FirstSeenScanID, &f.LastSeenScanID, &f.FixedScanID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan finding row: %w", err)
+		}
+		findings = append(findings, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate findings: %w", err)
+	}
+
+	return findings, nil
+}
+
+// RecordFindings records the findings a scan saw: new ones are opened, fixed ones that came back
+// are reopened, and open ones the scan didn't see are marked fixed by it, unless their rule is one
+// the scan didn't evaluate
+func (db *DB) RecordFindings(ctx context.Context, serverID, scanID uuid.UUID, findings []*Finding, unevaluated []string) error {
+	if unevaluated == nil {
+		unevaluated = []string{} // NULL would make "rule <> ALL" match nothing
+	}
+
+	return db.WithTransaction(ctx, func(tx pgx.Tx) error {
+		for _, f := range findings {
+			query := `
+				INSERT INTO findings (
+					server_id, fingerprint, check_name, rule, severity, tool_name, file_path, line, message,
+					first_seen_scan_id, last_seen_scan_id
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)
+				ON CONFLICT (server_id, fingerprint) DO UPDATE SET
+					severity = EXCLUDED.severity, tool_name = EXCLUDED.tool_name, file_path = EXCLUDED.file_path,
+					line = EXCLUDED.line, message = EXCLUDED.message,
+					last_seen = NOW(), last_seen_scan_id = EXCLUDED.last_seen_scan_id,
+					opened_at = CASE WHEN findings.fixed_at IS NULL THEN findings.opened_at ELSE NOW() END,
+					fixed_at = NULL, fixed_scan_id = NULL
+				RETURNING id, first_seen, last_seen, opened_at
+			`
+
+			err := tx.QueryRow(ctx, query,
+				serverID, f.Fingerprint, f.Check, f.Rule, f.Severity, f.ToolName, f.FilePath, f.Line, f.Message, scanID,
+			).Scan(&f.ID, &f.FirstSeen, &f.LastSeen, &f.OpenedAt)
+			if err != nil {
+				return fmt.Errorf("upsert finding: %w", err)
+			}
+
+			_, err = tx.Exec(ctx, `
+				INSERT INTO scan_findings (scan_id, finding_id) VALUES ($1, $2)
+				ON CONFLICT (scan_id, finding_id) DO NOTHING
+			`, scanID, f.ID)
+			if err != nil {
+				return fmt.Errorf("insert scan finding: %w", err)
+			}
+		}
+
+		_, err := tx.Exec(ctx, `
+			UPDATE findings SET fixed_at = NOW(), fixed_scan_id = $2
+			WHERE server_id = $1 AND fixed_at IS NULL AND rule <> ALL($3)
+			  AND id NOT IN (SELECT finding_id FROM scan_findings WHERE scan_id = $2)
+		`, serverID, scanID, unevaluated)
+		if err != nil {
+			return fmt.Errorf("mark fixed findings: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, "UPDATE scans SET findings_recorded = TRUE WHERE id = $1", scanID); err != nil {
+			return fmt.Errorf("mark findings recorded: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetFindingsForServer retrieves a server's findings, open, fixed or both when state is empty,
+// most recently opened first
+func (db *DB) GetFindingsForServer(ctx context.Context, serverID uuid.UUID, state string, limit, offset int) ([]*Finding, int, error) {
+	condition := ""
+	switch state {
+	case FindingOpen:
+		condition = " AND f.fixed_at IS NULL"
+	case FindingFixed:
+		condition = " AND f.fixed_at IS NOT NULL"
+	}
+
+	var total int
+	err := db.pool.QueryRow(ctx, "SELECT COUNT(*) FROM findings f WHERE f.server_id = $1"+condition, serverID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count findings: %w", err)
+	}
+
+	query := `
+		SELECT ` + findingColumns + `
+		FROM findings f
+		JOIN servers s ON s.id = f.server_id
+		WHERE f.server_id = $1` + condition + `
+		ORDER BY f.fixed_at IS NULL DESC, f.opened_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := db.pool.Query(ctx, query, serverID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query findings: %w", err)
+	}
+	findings, err := scanFindingRows(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return findings, total, nil
+}
+
+// GetFindingsForScan retrieves the findings a scan saw
+func (db *DB) GetFindingsForScan(ctx context.Context, scanID uuid.UUID) ([]*Finding, error) {
+	query := `
+		SELECT ` + findingColumns + `
+		FROM scan_findings sf
+		JOIN findings f ON f.id = sf.finding_id
+		JOIN servers s ON s.id = f.server_id
+		WHERE sf.scan_id = $1
+		ORDER BY f.check_name, f.rule, f.fingerprint
+	`
+
+	rows, err := db.pool.Query(ctx, query, scanID)
+	if err != nil {
+		return nil, fmt.Errorf("query scan findings: %w", err)
+	}
+	return scanFindingRows(rows)
+}
+
+// GetRecentlyOpenedFindings retrieves open findings of a severity across all servers,
+// most recently opened first
+func (db *DB) GetRecentlyOpenedFindings(ctx context.Context, severity string, limit int) ([]*Finding, error) {
+	query := `
+		SELECT ` + findingColumns + `
+		FROM findings f
+		JOIN servers s ON s.id = f.server_id
+		WHERE f.fixed_at IS NULL AND f.severity = $1
+		ORDER BY f.opened_at DESC
+		LIMIT $2
+	`
+
+	rows, err := db.pool.Query(ctx, query, severity, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query opened findings: %w", err)
+	}
+	return scanFindingRows(rows)
+}
+
+// GetFindingsScans resolves the two scans to compare: to defaults to the latest scan with recorded
+// findings, and from to the one before it; returns a nil from when to is the first
+func (db *DB) GetFindingsScans(ctx context.Context, serverID uuid.UUID, from, to *uuid.UUID) (*Scan, *Scan, error) {
+	var toScan *Scan
+	if to != nil {
+		scan, err := db.GetScan(ctx, *to)
+		if err != nil {
+			return nil, nil, err
+		}
+		toScan = scan
+	} else {
+		query := `
+			SELECT ` + scanColumns + `
+			FROM scans
+			WHERE server_id = $1 AND findings_recorded
+			ORDER BY scanned_at DESC
+			LIMIT 1
+		`
+		scan, err := scanScanRow(db.pool.QueryRow(ctx, query, serverID))
+		if err == pgx.ErrNoRows {
+			return nil, nil, fmt.Errorf("scan with recorded findings %w", ErrNotFound)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("get latest findings scan: %w", err)
+		}
+		toScan = scan
+	}
+
+	if from != nil {
+		fromScan, err := db.GetScan(ctx, *from)
+		if err != nil {
+			return nil, nil, err
+		}
+		return fromScan, toScan, nil
+	}
+
+	query := `
+		SELECT ` + scanColumns + `
+		FROM scans
+		WHERE server_id = $1 AND findings_recorded AND id <> $2 AND scanned_at <= $3
+		ORDER BY scanned_at DESC
+		LIMIT 1
+	`
+	fromScan, err := scanScanRow(db.pool.QueryRow(ctx, query, serverID, toScan.ID, toScan.ScannedAt))
+	if err == pgx.ErrNoRows {
+		return nil, toScan, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("get previous findings scan: %w", err)
+	}
+
+	return fromScan, toScan, nil
+}
+
+// CountOpenFindings counts a server's open findings by severity
+func (db *DB) CountOpenFindings(ctx context.Context, serverID uuid.UUID) (map[string]int, error) {
+	rows, err := db.pool.Query(ctx, `
+		SELECT severity, COUNT(*) FROM findings
+		WHERE server_id = $1 AND fixed_at IS NULL
+		GROUP BY severity
+	`, serverID)
+	if err != nil {
+		return nil, fmt.Errorf("count open findings: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{"critical": 0, "warning": 0}
+	for rows.Next() {
+		var severity string
+		var count int
+		if err := rows.Scan(&severity, &count); err != nil {
+			return nil, fmt.Errorf("scan finding count: %w", err)
+		}
+		counts[severity] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate finding counts: %w", err)
+	}
+
+	return counts, nil
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 // Mutation represents a change in a tool definition between scans
@@ -14,39 +15,71 @@ type Mutation struct {
 	ID              uuid.UUID       `json:"id"`
 	ServerID        uuid.UUID       `json:"server_id"`
 	ToolName        string          `json:"tool_name"`
+	Kind            string          `json:"kind"` // "description" or "output"
 	OldHash         string          `json:"old_hash"`
 	NewHash         string          `json:"new_hash"`
 	OldDescription  *string         `json:"old_description,omitempty"`
 	NewDescription  *string         `json:"new_description,omitempty"`
 	OldParameters   json.RawMessage `json:"old_parameters,omitempty"`
 	NewParameters   json.RawMessage `json:"new_parameters,omitempty"`
+	OldOutputs      json.RawMessage `json:"old_outputs,omitempty"`
+	NewOutputs      json.RawMessage `json:"new_outputs,omitempty"`
 	Severity        string          `json:"severity"`
 	SeverityReason  *string         `json:"severity_reason,omitempty"`
+	ScanID          *uuid.UUID      `json:"scan_id,omitempty"`          // Scan whose snapshot showed the change
+	PreviousScanID  *uuid.UUID      `json:"previous_scan_id,omitempty"` // Snapshot it was compared against
+	Diff            json.RawMessage `json:"diff,omitempty"`             // Structured diff, see scanner.MutationDiff
 	DetectedAt      time.Time       `json:"detected_at"`
 }
 
+// mutationColumns lists the mutations columns in the order scanMutationRow expects
+const mutationColumns = `id, server_id, tool_name, kind, old_hash, new_hash,
+			   old_description, new_description, old_parameters, new_parameters,
+			   old_outputs, new_outputs, severity, severity_reason, scan_id, previous_scan_id, diff, detected_at`
+
+// scanMutationRow reads a mutation from a row selected with mutationColumns
+func scanMutationRow(row pgx.Row) (*Mutation, error) {
+	mutation := &Mutation{}
+	err := row.Scan(
+		&mutation.ID, &mutation.ServerID, &mutation.ToolName, &mutation.Kind,
+		&mutation.OldHash, &mutation.NewHash,
+		&mutation.OldDescription, &mutation.NewDescription,
+		&mutation.OldParameters, &mutation.NewParameters,
+		&mutation.OldOutputs, &mutation.NewOutputs,
+		&mutation.Severity, &mutation.SeverityReason,
+		&mutation.ScanID, &mutation.PreviousScanID, &mutation.Diff, &mutation.DetectedAt,
+	)
+	return mutation, err
+}
+
 // InsertMutation creates a new mutation record
 func (db *DB) InsertMutation(ctx context.Context, mutation *Mutation) error {
 	query := `
 		INSERT INTO mutations (
-			server_id, tool_name, old_hash, new_hash,
+			server_id, tool_name, kind, old_hash, new_hash,
 			old_description, new_description, old_parameters, new_parameters,
-			severity, severity_reason
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			old_outputs, new_outputs, severity, severity_reason, scan_id, previous_scan_id, diff
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		RETURNING id, detected_at
 	`
 
 	err := db.pool.QueryRow(ctx, query,
 		mutation.ServerID,
 		mutation.ToolName,
+		mutation.Kind,
 		mutation.OldHash,
 		mutation.NewHash,
 		mutation.OldDescription,
 		mutation.NewDescription,
 		mutation.OldParameters,
 		mutation.NewParameters,
+		mutation.OldOutputs,
+		mutation.NewOutputs,
 		mutation.Severity,
 		mutation.SeverityReason,
+		mutation.ScanID,
+		mutation.PreviousScanID,
+		mutation.Diff,
 	).Scan(&mutation.ID, &mutation.DetectedAt)
 
 	if err != nil {
@@ -56,6 +89,26 @@ func (db *DB) InsertMutation(ctx context.Context, mutation *Mutation) error {
 	return nil
 }
 
+// GetMutation retrieves a mutation by ID
+func (db *DB) GetMutation(ctx context.Context, id uuid.UUID) (*Mutation, error) {
+	query := `
+		SELECT ` + mutationColumns + `
+		FROM mutations
+		WHERE id = $1
+	`
+
+	mutation, err := scanMutationRow(db.pool.QueryRow(ctx, query, id))
+
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("mutation not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get mutation: %w", err)
+	}
+
+	return mutation, nil
+}
+
 // GetMutationsForServer retrieves mutation history for a server
 func (db *DB) GetMutationsForServer(ctx context.Context, serverID uuid.UUID, limit, offset int) ([]*Mutation, int, error) {
 	// Get total count
@@ -67,9 +120,7 @@ func (db *DB) GetMutationsForServer(ctx context.Context, serverID uuid.UUID, lim
 
 	// Get paginated results
 	query := `
-		SELECT id, server_id, tool_name, old_hash, new_hash,
-			   old_description, new_description, old_parameters, new_parameters,
-			   severity, severity_reason, detected_at
+		SELECT ` + mutationColumns + `
 		FROM mutations
 		WHERE server_id = $1
 		ORDER BY detected_at DESC
@@ -84,14 +135,7 @@ func (db *DB) GetMutationsForServer(ctx context.Context, serverID uuid.UUID, lim
 
 	mutations := make([]*Mutation, 0)
 	for rows.Next() {
-		mutation := &Mutation{}
-		err := rows.Scan(
-			&mutation.ID, &mutation.ServerID, &mutation.ToolName,
-			&mutation.OldHash, &mutation.NewHash,
-			&mutation.OldDescription, &mutation.NewDescription,
-			&mutation.OldParameters, &mutation.NewParameters,
-			&mutation.Severity, &mutation.SeverityReason, &mutation.DetectedAt,
-		)
+		mutation, err := scanMutationRow(rows)
 		if err != nil {
 			return nil, 0, fmt.Errorf("scan mutation row: %w", err)
 		}
@@ -104,9 +148,7 @@ func (db *DB) GetMutationsForServer(ctx context.Context, serverID uuid.UUID, lim
 // GetRecentMutations retrieves recent mutations across all servers
 func (db *DB) GetRecentMutations(ctx context.Context, limit int) ([]*Mutation, error) {
 	query := `
-		SELECT id, server_id, tool_name, old_hash, new_hash,
-			   old_description, new_description, old_parameters, new_parameters,
-			   severity, severity_reason, detected_at
+		SELECT ` + mutationColumns + `
 		FROM mutations
 		ORDER BY detected_at DESC
 		LIMIT $1
@@ -120,14 +162,7 @@ func (db *DB) GetRecentMutations(ctx context.Context, limit int) ([]*Mutation, e
 
 	mutations := make([]*Mutation, 0)
 	for rows.Next() {
-		mutation := &Mutation{}
-		err := rows.Scan(
-			&mutation.ID, &mutation.ServerID, &mutation.ToolName,
-			&mutation.OldHash, &mutation.NewHash,
-			&mutation.OldDescription, &mutation.NewDescription,
-			&mutation.OldParameters, &mutation.NewParameters,
-			&mutation.Severity, &mutation.SeverityReason, &mutation.DetectedAt,
-		)
+		mutation, err := scanMutationRow(rows)
 		if err != nil {
 			return nil, fmt.Errorf("scan mutation row: %w", err)
 		}
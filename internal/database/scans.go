@@ -12,18 +12,75 @@ import (
 
 // Scan represents a security scan of an MCP server
 type Scan struct {
-	ID                     uuid.UUID       `json:"id"`
-	ServerID               uuid.UUID       `json:"server_id"`
-	ScannedAt              time.Time       `json:"scanned_at"`
-	ToolIntegrityStatus    string          `json:"tool_integrity_status"`
-	ToolIntegrityDetails   json.RawMessage `json:"tool_integrity_details"`
-	AuthStatus             string          `json:"auth_status"`
-	AuthDetails            json.RawMessage `json:"auth_details"`
-	ExposureStatus         string          `json:"exposure_status"`
-	ExposureDetails        json.RawMessage `json:"exposure_details"`
-	TrustScore             int             `json:"trust_score"`
-	ToolDefinitionsHash    *string         `json:"tool_definitions_hash,omitempty"`
-	ScanDurationMs         *int            `json:"scan_duration_ms,omitempty"`
+	ID                   uuid.UUID       `json:"id"`
+	ServerID             uuid.UUID       `json:"server_id"`
+	ScannedAt            time.Time       `json:"scanned_at"`
+	ToolIntegrityStatus  string          `json:"tool_integrity_status"`
+	ToolIntegrityDetails json.RawMessage `json:"tool_integrity_details"`
+	AuthStatus           string          `json:"auth_status"`
+	AuthDetails          json.RawMessage `json:"auth_details"`
+	ExposureStatus       string          `json:"exposure_status"`
+	ExposureDetails      json.RawMessage `json:"exposure_details"`
+	TrustScore           int             `json:"trust_score"`
+	ToolDefinitionsHash  *string         `json:"tool_definitions_hash,omitempty"`
+	ScanDurationMs       *int            `json:"scan_duration_ms,omitempty"`
+	ContextDetails       json.RawMessage `json:"context_details,omitempty"`
+
+	// What was analysed; Ref is set for on-demand scans of a tag, branch or commit
+	CommitSHA      *string `json:"commit_sha,omitempty"`
+	Branch         *string `json:"branch,omitempty"`
+	Ref            *string `json:"ref,omitempty"`
+	PackageVersion *string `json:"package_version,omitempty"`
+
+	// Whether the scan's tool set and findings are stored; scans from before they were kept have none
+	ToolsRecorded    bool `json:"tools_recorded"`
+	FindingsRecorded bool `json:"findings_recorded"`
+}
+
+// ShortCommit returns the abbreviated commit SHA the scan analysed, or "" if unknown
+func (s *Scan) ShortCommit() string {
+	if s.CommitSHA == nil {
+		return ""
+	}
+	if len(*s.CommitSHA) > 12 {
+		return (*s.CommitSHA)[:12]
+	}
+	return *s.CommitSHA
+}
+
+// ServerVersion is the latest scan of one package version of a server
+type ServerVersion struct {
+	Version             string    `json:"version"`
+	ScanID              uuid.UUID `json:"scan_id"`
+	CommitSHA           *string   `json:"commit_sha,omitempty"`
+	Ref                 *string   `json:"ref,omitempty"`
+	TrustScore          int       `json:"trust_score"`
+	ToolIntegrityStatus string    `json:"tool_integrity_status"`
+	AuthStatus          string    `json:"auth_status"`
+	ExposureStatus      string    `json:"exposure_status"`
+	ScannedAt           time.Time `json:"scanned_at"`
+}
+
+// scanColumns lists the scans columns in the order scanScanRow expects
+const scanColumns = `id, server_id, scanned_at, tool_integrity_status, tool_integrity_details,
+			   auth_status, auth_details, exposure_status, exposure_details,
+			   trust_score, tool_definitions_hash, scan_duration_ms, context_details,
+			   commit_sha, branch, ref, package_version, tools_recorded, findings_recorded`
+
+// scanScanRow reads a scan from a row selected with scanColumns
+func scanScanRow(row pgx.Row) (*Scan, error) {
+	scan := &Scan{}
+	err := row.Scan(
+		&scan.ID, &scan.ServerID, &scan.ScannedAt,
+		&scan.ToolIntegrityStatus, &scan.ToolIntegrityDetails,
+		&scan.AuthStatus, &scan.AuthDetails,
+		&scan.ExposureStatus, &scan.ExposureDetails,
+		&scan.TrustScore, &scan.ToolDefinitionsHash, &scan.ScanDurationMs,
+		&scan.ContextDetails,
+		&scan.CommitSHA, &scan.Branch, &scan.Ref, &scan.PackageVersion,
+		&scan.ToolsRecorded, &scan.FindingsRecorded,
+	)
+	return scan, err
 }
 
 // InsertScan creates a new scan record
@@ -32,8 +89,9 @@ func (db *DB) InsertScan(ctx context.Context, scan *Scan) error {
 		INSERT INTO scans (
 			server_id, tool_integrity_status, tool_integrity_details,
 			auth_status, auth_details, exposure_status, exposure_details,
-			trust_score, tool_definitions_hash, scan_duration_ms
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			trust_score, tool_definitions_hash, scan_duration_ms, context_details,
+			commit_sha, branch, ref, package_version
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 		RETURNING id, scanned_at
 	`
 
@@ -48,6 +106,11 @@ func (db *DB) InsertScan(ctx context.Context, scan *Scan) error {
 		scan.TrustScore,
 		scan.ToolDefinitionsHash,
 		scan.ScanDurationMs,
+		scan.ContextDetails,
+		scan.CommitSHA,
+		scan.Branch,
+		scan.Ref,
+		scan.PackageVersion,
 	).Scan(&scan.ID, &scan.ScannedAt)
 
 	if err != nil {
@@ -60,24 +123,15 @@ func (db *DB) InsertScan(ctx context.Context, scan *Scan) error {
 // GetScan retrieves a scan by ID
 func (db *DB) GetScan(ctx context.Context, id uuid.UUID) (*Scan, error) {
 	query := `
-		SELECT id, server_id, scanned_at, tool_integrity_status, tool_integrity_details,
-			   auth_status, auth_details, exposure_status, exposure_details,
-			   trust_score, tool_definitions_hash, scan_duration_ms
+		SELECT ` + scanColumns + `
 		FROM scans
 		WHERE id = $1
 	`
 
-	scan := &Scan{}
-	err := db.pool.QueryRow(ctx, query, id).Scan(
-		&scan.ID, &scan.ServerID, &scan.ScannedAt,
-		&scan.ToolIntegrityStatus, &scan.ToolIntegrityDetails,
-		&scan.AuthStatus, &scan.AuthDetails,
-		&scan.ExposureStatus, &scan.ExposureDetails,
-		&scan.TrustScore, &scan.ToolDefinitionsHash, &scan.ScanDurationMs,
-	)
+	scan, err := scanScanRow(db.pool.QueryRow(ctx, query, id))
 
 	if err == pgx.ErrNoRows {
-		return nil, fmt.Errorf("scan not found")
+		return nil, fmt.Errorf("scan %w", ErrNotFound)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("get scan: %w", err)
@@ -86,26 +140,17 @@ func (db *DB) GetScan(ctx context.Context, id uuid.UUID) (*Scan, error) {
 	return scan, nil
 }
 
-// GetLatestScanForServer retrieves the most recent scan for a server
+// GetLatestScanForServer retrieves the most recent scan of a server's default branch
 func (db *DB) GetLatestScanForServer(ctx context.Context, serverID uuid.UUID) (*Scan, error) {
 	query := `
-		SELECT id, server_id, scanned_at, tool_integrity_status, tool_integrity_details,
-			   auth_status, auth_details, exposure_status, exposure_details,
-			   trust_score, tool_definitions_hash, scan_duration_ms
+		SELECT ` + scanColumns + `
 		FROM scans
-		WHERE server_id = $1
+		WHERE server_id = $1 AND ref IS NULL
 		ORDER BY scanned_at DESC
 		LIMIT 1
 	`
 
-	scan := &Scan{}
-	err := db.pool.QueryRow(ctx, query, serverID).Scan(
-		&scan.ID, &scan.ServerID, &scan.ScannedAt,
-		&scan.ToolIntegrityStatus, &scan.ToolIntegrityDetails,
-		&scan.AuthStatus, &scan.AuthDetails,
-		&scan.ExposureStatus, &scan.ExposureDetails,
-		&scan.TrustScore, &scan.ToolDefinitionsHash, &scan.ScanDurationMs,
-	)
+	scan, err := scanScanRow(db.pool.QueryRow(ctx, query, serverID))
 
 	if err == pgx.ErrNoRows {
 		return nil, nil // No scans yet
@@ -117,6 +162,28 @@ func (db *DB) GetLatestScanForServer(ctx context.Context, serverID uuid.UUID) (*
 	return scan, nil
 }
 
+// GetScanAsOf retrieves the latest default-branch scan with a tool snapshot taken at or before a time
+func (db *DB) GetScanAsOf(ctx context.Context, serverID uuid.UUID, at time.Time) (*Scan, error) {
+	query := `
+		SELECT ` + scanColumns + `
+		FROM scans
+		WHERE server_id = $1 AND ref IS NULL AND tools_recorded AND scanned_at <= $2
+		ORDER BY scanned_at DESC
+		LIMIT 1
+	`
+
+	scan, err := scanScanRow(db.pool.QueryRow(ctx, query, serverID, at))
+
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("no scan at or before %s", at.Format(time.RFC3339))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get scan as of: %w", err)
+	}
+
+	return scan, nil
+}
+
 // GetScanHistory retrieves scan history for a server
 func (db *DB) GetScanHistory(ctx context.Context, serverID uuid.UUID, limit, offset int) ([]*Scan, int, error) {
 	// Get total count
@@ -128,9 +195,7 @@ func (db *DB) GetScanHistory(ctx context.Context, serverID uuid.UUID, limit, off
 
 	// Get paginated results
 	query := `
-		SELECT id, server_id, scanned_at, tool_integrity_status, tool_integrity_details,
-			   auth_status, auth_details, exposure_status, exposure_details,
-			   trust_score, tool_definitions_hash, scan_duration_ms
+		SELECT ` + scanColumns + `
 		FROM scans
 		WHERE server_id = $1
 		ORDER BY scanned_at DESC
@@ -145,14 +210,7 @@ func (db *DB) GetScanHistory(ctx context.Context, serverID uuid.UUID, limit, off
 
 	scans := make([]*Scan, 0)
 	for rows.Next() {
-		scan := &Scan{}
-		err := rows.Scan(
-			&scan.ID, &scan.ServerID, &scan.ScannedAt,
-			&scan.ToolIntegrityStatus, &scan.ToolIntegrityDetails,
-			&scan.AuthStatus, &scan.AuthDetails,
-			&scan.ExposureStatus, &scan.ExposureDetails,
-			&scan.TrustScore, &scan.ToolDefinitionsHash, &scan.ScanDurationMs,
-		)
+		scan, err := scanScanRow(rows)
 		if err != nil {
 			return nil, 0, fmt.Errorf("scan row: %w", err)
 		}
@@ -162,16 +220,52 @@ func (db *DB) GetScanHistory(ctx context.Context, serverID uuid.UUID, limit, off
 	return scans, total, nil
 }
 
+// GetServerVersions retrieves the latest scan of each package version of a server, newest first
+func (db *DB) GetServerVersions(ctx context.Context, serverID uuid.UUID) ([]*ServerVersion, error) {
+	query := `
+		SELECT * FROM (
+			SELECT DISTINCT ON (package_version)
+				   package_version, id, commit_sha, ref, trust_score,
+				   tool_integrity_status, auth_status, exposure_status, scanned_at
+			FROM scans
+			WHERE server_id = $1 AND package_version IS NOT NULL
+			ORDER BY package_version, scanned_at DESC
+		) latest
+		ORDER BY scanned_at DESC
+	`
+
+	rows, err := db.pool.Query(ctx, query, serverID)
+	if err != nil {
+		return nil, fmt.Errorf("query server versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make([]*ServerVersion, 0)
+	for rows.Next() {
+		v := &ServerVersion{}
+		err := rows.Scan(
+			&v.Version, &v.ScanID, &v.CommitSHA, &v.Ref, &v.TrustScore,
+			&v.ToolIntegrityStatus, &v.AuthStatus, &v.ExposureStatus, &v.ScannedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan version row: %w", err)
+		}
+		versions = append(versions, v)
+	}
+
+	return versions, nil
+}
+
 // GetRecentCriticalScans retrieves recently scanned servers with critical findings
+// On-demand scans of tags and branches are left out; they don't describe the server as it is now
 func (db *DB) GetRecentCriticalScans(ctx context.Context, limit int) ([]*Scan, error) {
 	query := `
-		SELECT id, server_id, scanned_at, tool_integrity_status, tool_integrity_details,
-			   auth_status, auth_details, exposure_status, exposure_details,
-			   trust_score, tool_definitions_hash, scan_duration_ms
+		SELECT ` + scanColumns + `
 		FROM scans
-		WHERE tool_integrity_status = 'critical'
-		   OR auth_status = 'critical'
-		   OR exposure_status = 'critical'
+		WHERE ref IS NULL
+		  AND (tool_integrity_status = 'critical'
+		    OR auth_status = 'critical'
+		    OR exposure_status = 'critical')
 		ORDER BY scanned_at DESC
 		LIMIT $1
 	`
@@ -184,14 +278,7 @@ func (db *DB) GetRecentCriticalScans(ctx context.Context, limit int) ([]*Scan, e
 
 	scans := make([]*Scan, 0)
 	for rows.Next() {
-		scan := &Scan{}
-		err := rows.Scan(
-			&scan.ID, &scan.ServerID, &scan.ScannedAt,
-			&scan.ToolIntegrityStatus, &scan.ToolIntegrityDetails,
-			&scan.AuthStatus, &scan.AuthDetails,
-			&scan.ExposureStatus, &scan.ExposureDetails,
-			&scan.TrustScore, &scan.ToolDefinitionsHash, &scan.ScanDurationMs,
-		)
+		scan, err := scanScanRow(rows)
 		if err != nil {
 			return nil, fmt.Errorf("scan row: %w", err)
 		}
@@ -225,9 +312,10 @@ func (db *DB) GetStats(ctx context.Context) (map[string]interface{}, error) {
 	var criticalFindings int
 	err = db.pool.QueryRow(ctx, `
 		SELECT COUNT(*) FROM scans
-		WHERE tool_integrity_status = 'critical'
-		   OR auth_status = 'critical'
-		   OR exposure_status = 'critical'
+		WHERE ref IS NULL
+		  AND (tool_integrity_status = 'critical'
+		    OR auth_status = 'critical'
+		    OR exposure_status = 'critical')
 	`).Scan(&criticalFindings)
 	if err != nil {
 		return nil, fmt.Errorf("count critical findings: %w", err)
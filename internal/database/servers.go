@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,24 +12,59 @@ import (
 
 // Server represents an MCP server
 type Server struct {
-	ID              uuid.UUID  `json:"id"`
-	Name            string     `json:"name"`
-	SourceURL       string     `json:"source_url"`
-	PackageRegistry *string    `json:"package_registry,omitempty"`
-	PackageName     *string    `json:"package_name,omitempty"`
-	Description     *string    `json:"description,omitempty"`
-	Author          *string    `json:"author,omitempty"`
-	License         *string    `json:"license,omitempty"`
-	Stars           int        `json:"stars"`
-	Transport       *string    `json:"transport,omitempty"`
-	ToolsCount      int        `json:"tools_count"`
-	TrustScore      int        `json:"trust_score"`
-	FirstSeen       time.Time  `json:"first_seen"`
-	LastScanned     *time.Time `json:"last_scanned,omitempty"`
-	ScanStatus      string     `json:"scan_status"`
-	ScanError       *string    `json:"scan_error,omitempty"`
-	CreatedAt       time.Time  `json:"created_at"`
-	UpdatedAt       time.Time  `json:"updated_at"`
+	ID              uuid.UUID `json:"id"`
+	Name            string    `json:"name"`
+	SourceURL       string    `json:"source_url"`
+	PackageRegistry *string   `json:"package_registry,omitempty"`
+	PackageName     *string   `json:"package_name,omitempty"`
+	Description     *string   `json:"description,omitempty"`
+	Author          *string   `json:"author,omitempty"`
+	License         *string   `json:"license,omitempty"`
+	Stars           int       `json:"stars"`
+	Transport       *string   `json:"transport,omitempty"` // Primary transport, the most exposed one
+	Transports      []string  `json:"transports,omitempty"`
+	// Live instances seen by an external scanner, distinct from the static exposure check
+	ObservedExposure *ObservedExposure `json:"observed_exposure,omitempty"`
+	ToolsCount       int               `json:"tools_count"`
+	TrustScore       int               `json:"trust_score"`
+	FirstSeen        time.Time         `json:"first_seen"`
+	LastScanned      *time.Time        `json:"last_scanned,omitempty"`
+	ScanStatus       string            `json:"scan_status"`
+	ScanError        *string           `json:"scan_error,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+
+	// Command the README tells users to run, with the risks found in it
+	InstallCommand  *string  `json:"install_command,omitempty"`
+	InstallWarnings []string `json:"install_warnings,omitempty"`
+	// "runtime" when tool descriptions, schemas or the tool list are fetched or computed at runtime
+	MutationRisk *string `json:"mutation_risk,omitempty"`
+}
+
+// MutableAtRuntime reports whether the last scan found tool definitions decided at runtime
+func (s *Server) MutableAtRuntime() bool {
+	return s.MutationRisk != nil && *s.MutationRisk == "runtime"
+}
+
+// serverColumns lists the servers columns in the order scanServerRow expects
+const serverColumns = `id, name, source_url, package_registry, package_name,
+			   description, author, license, stars, transport, transports, tools_count,
+			   trust_score, first_seen, last_scanned, scan_status, scan_error,
+			   created_at, updated_at, observed_exposure, install_command, install_warnings,
+			   mutation_risk`
+
+// scanServerRow reads a server from a row selected with serverColumns
+func scanServerRow(row pgx.Row) (*Server, error) {
+	server := &Server{}
+	err := row.Scan(
+		&server.ID, &server.Name, &server.SourceURL, &server.PackageRegistry,
+		&server.PackageName, &server.Description, &server.Author, &server.License,
+		&server.Stars, &server.Transport, &server.Transports, &server.ToolsCount,
+		&server.TrustScore, &server.FirstSeen, &server.LastScanned, &server.ScanStatus,
+		&server.ScanError, &server.CreatedAt, &server.UpdatedAt, &server.ObservedExposure,
+		&server.InstallCommand, &server.InstallWarnings, &server.MutationRisk,
+	)
+	return server, err
 }
 
 // UpsertServer inserts or updates a server (dedup by source_url)
@@ -72,22 +108,12 @@ func (db *DB) UpsertServer(ctx context.Context, server *Server) error {
 // GetServer retrieves a server by ID
 func (db *DB) GetServer(ctx context.Context, id uuid.UUID) (*Server, error) {
 	query := `
-		SELECT id, name, source_url, package_registry, package_name,
-			   description, author, license, stars, transport, tools_count,
-			   trust_score, first_seen, last_scanned, scan_status, scan_error,
-			   created_at, updated_at
+		SELECT ` + serverColumns + `
 		FROM servers
 		WHERE id = $1
 	`
 
-	server := &Server{}
-	err := db.pool.QueryRow(ctx, query, id).Scan(
-		&server.ID, &server.Name, &server.SourceURL, &server.PackageRegistry,
-		&server.PackageName, &server.Description, &server.Author, &server.License,
-		&server.Stars, &server.Transport, &server.ToolsCount, &server.TrustScore,
-		&server.FirstSeen, &server.LastScanned, &server.ScanStatus, &server.ScanError,
-		&server.CreatedAt, &server.UpdatedAt,
-	)
+	server, err := scanServerRow(db.pool.QueryRow(ctx, query, id))
 
 	if err == pgx.ErrNoRows {
 		return nil, fmt.Errorf("server not found")
@@ -99,6 +125,38 @@ func (db *DB) GetServer(ctx context.Context, id uuid.UUID) (*Server, error) {
 	return server, nil
 }
 
+// FindServer retrieves a server by ID, package name, source URL or name, in that order of preference
+// registry ("npm" or "pypi") narrows package name matches when set
+func (db *DB) FindServer(ctx context.Context, identifier, registry string) (*Server, error) {
+	if id, err := uuid.Parse(identifier); err == nil {
+		return db.GetServer(ctx, id)
+	}
+
+	query := `
+		SELECT ` + serverColumns + `
+		FROM servers
+		WHERE (package_name = $1 AND ($2 = '' OR package_registry = $2))
+		   OR source_url = $3
+		   OR name = $1
+		ORDER BY (package_name = $1 AND ($2 = '' OR package_registry = $2)) IS TRUE DESC,
+		         (source_url = $3) IS TRUE DESC,
+		         stars DESC NULLS LAST
+		LIMIT 1
+	`
+
+	sourceURL := strings.TrimSuffix(strings.TrimSuffix(identifier, "/"), ".git")
+	server, err := scanServerRow(db.pool.QueryRow(ctx, query, identifier, registry, sourceURL))
+
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("server not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find server: %w", err)
+	}
+
+	return server, nil
+}
+
 // ListServers retrieves servers with pagination
 func (db *DB) ListServers(ctx context.Context, limit, offset int) ([]*Server, int, error) {
 	// Get total count
@@ -110,10 +168,7 @@ func (db *DB) ListServers(ctx context.Context, limit, offset int) ([]*Server, in
 
 	// Get paginated results
 	query := `
-		SELECT id, name, source_url, package_registry, package_name,
-			   description, author, license, stars, transport, tools_count,
-			   trust_score, first_seen, last_scanned, scan_status, scan_error,
-			   created_at, updated_at
+		SELECT ` + serverColumns + `
 		FROM servers
 		ORDER BY trust_score DESC, created_at DESC
 		LIMIT $1 OFFSET $2
@@ -127,14 +182,7 @@ func (db *DB) ListServers(ctx context.Context, limit, offset int) ([]*Server, in
 
 	servers := make([]*Server, 0)
 	for rows.Next() {
-		server := &Server{}
-		err := rows.Scan(
-			&server.ID, &server.Name, &server.SourceURL, &server.PackageRegistry,
-			&server.PackageName, &server.Description, &server.Author, &server.License,
-			&server.Stars, &server.Transport, &server.ToolsCount, &server.TrustScore,
-			&server.FirstSeen, &server.LastScanned, &server.ScanStatus, &server.ScanError,
-			&server.CreatedAt, &server.UpdatedAt,
-		)
+		server, err := scanServerRow(rows)
 		if err != nil {
 			return nil, 0, fmt.Errorf("scan server row: %w", err)
 		}
@@ -160,10 +208,7 @@ func (db *DB) SearchServers(ctx context.Context, query string, limit, offset int
 
 	// Get paginated search results
 	searchQuery := `
-		SELECT id, name, source_url, package_registry, package_name,
-			   description, author, license, stars, transport, tools_count,
-			   trust_score, first_seen, last_scanned, scan_status, scan_error,
-			   created_at, updated_at
+		SELECT ` + serverColumns + `
 		FROM servers
 		WHERE to_tsvector('english', coalesce(name, '') || ' ' || coalesce(description, ''))
 		@@ plainto_tsquery('english', $1)
@@ -179,14 +224,7 @@ func (db *DB) SearchServers(ctx context.Context, query string, limit, offset int
 
 	servers := make([]*Server, 0)
 	for rows.Next() {
-		server := &Server{}
-		err := rows.Scan(
-			&server.ID, &server.Name, &server.SourceURL, &server.PackageRegistry,
-			&server.PackageName, &server.Description, &server.Author, &server.License,
-			&server.Stars, &server.Transport, &server.ToolsCount, &server.TrustScore,
-			&server.FirstSeen, &server.LastScanned, &server.ScanStatus, &server.ScanError,
-			&server.CreatedAt, &server.UpdatedAt,
-		)
+		server, err := scanServerRow(rows)
 		if err != nil {
 			return nil, 0, fmt.Errorf("scan server row: %w", err)
 		}
@@ -211,32 +249,59 @@ func (db *DB) UpdateServerScanStatus(ctx context.Context, id uuid.UUID, status s
 }
 
 // UpdateServerAfterScan updates server fields after a successful scan
-func (db *DB) UpdateServerAfterScan(ctx context.Context, id uuid.UUID, trustScore, toolsCount int, transport string) error {
+func (db *DB) UpdateServerAfterScan(ctx context.Context, id uuid.UUID, trustScore, toolsCount int, transport string, transports []string) error {
 	query := `
 		UPDATE servers
-		SET trust_score = $1, tools_count = $2, transport = $3,
+		SET trust_score = $1, tools_count = $2, transport = $3, transports = $4,
 		    last_scanned = NOW(), scan_status = 'completed', scan_error = NULL, updated_at = NOW()
-		WHERE id = $4
+		WHERE id = $5
 	`
-	err := db.Exec(ctx, query, trustScore, toolsCount, transport, id)
+	err := db.Exec(ctx, query, trustScore, toolsCount, transport, transports, id)
 	if err != nil {
 		return fmt.Errorf("update server after scan: %w", err)
 	}
 	return nil
 }
 
+// UpdateServerInstallCommand stores the extracted install command, or clears it when command is nil
+func (db *DB) UpdateServerInstallCommand(ctx context.Context, id uuid.UUID, command *string, warnings []string) error {
+	query := `
+		UPDATE servers
+		SET install_command = $1, install_warnings = $2, updated_at = NOW()
+		WHERE id = $3
+	`
+	err := db.Exec(ctx, query, command, warnings, id)
+	if err != nil {
+		return fmt.Errorf("update server install command: %w", err)
+	}
+	return nil
+}
+
+// UpdateServerMutationRisk stores whether a server's tool definitions can change at runtime
+func (db *DB) UpdateServerMutationRisk(ctx context.Context, id uuid.UUID, risk string) error {
+	query := `
+		UPDATE servers
+		SET mutation_risk = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+	err := db.Exec(ctx, query, risk, id)
+	if err != nil {
+		return fmt.Errorf("update server mutation risk: %w", err)
+	}
+	return nil
+}
+
 // GetServersToScan retrieves servers that need scanning
 func (db *DB) GetServersToScan(ctx context.Context, limit int) ([]*Server, error) {
 	query := `
-		SELECT id, name, source_url, package_registry, package_name,
-			   description, author, license, stars, transport, tools_count,
-			   trust_score, first_seen, last_scanned, scan_status, scan_error,
-			   created_at, updated_at
+		SELECT ` + serverColumns + `
 		FROM servers
 		WHERE scan_status = 'pending'
 		   OR (scan_status = 'completed' AND last_scanned < NOW() - INTERVAL '24 hours')
 		   OR (scan_status = 'failed' AND last_scanned < NOW() - INTERVAL '7 days')
+		   OR id IN (SELECT server_id FROM scan_queue WHERE claimed_at IS NULL)
 		ORDER BY
+			(SELECT priority FROM scan_queue WHERE scan_queue.server_id = servers.id) DESC NULLS LAST,
 			CASE
 				WHEN scan_status = 'pending' THEN 1
 				WHEN scan_status = 'completed' THEN 2
@@ -254,14 +319,7 @@ func (db *DB) GetServersToScan(ctx context.Context, limit int) ([]*Server, error
 
 	servers := make([]*Server, 0)
 	for rows.Next() {
-		server := &Server{}
-		err := rows.Scan(
-			&server.ID, &server.Name, &server.SourceURL, &server.PackageRegistry,
-			&server.PackageName, &server.Description, &server.Author, &server.License,
-			&server.Stars, &server.Transport, &server.ToolsCount, &server.TrustScore,
-			&server.FirstSeen, &server.LastScanned, &server.ScanStatus, &server.ScanError,
-			&server.CreatedAt, &server.UpdatedAt,
-		)
+		server, err := scanServerRow(rows)
 		if err != nil {
 			return nil, fmt.Errorf("scan server row: %w", err)
 		}
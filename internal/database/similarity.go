@@ -0,0 +1,212 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mcpsek/mcpsek/internal/similarity"
+)
+
+// SimilarTool is a current tool whose description is a near-duplicate of another
+type SimilarTool struct {
+	ToolID      uuid.UUID `json:"tool_id"`
+	ServerID    uuid.UUID `json:"server_id"`
+	ServerName  string    `json:"server_name"`
+	ToolName    string    `json:"tool_name"`
+	Description *string   `json:"description,omitempty"`
+	Similarity  float64   `json:"similarity"` // Estimated Jaccard similarity of the descriptions
+}
+
+// SimilarityFlag marks a tool for review because it is a near-duplicate of a critical tool
+type SimilarityFlag struct {
+	ID               uuid.UUID  `json:"id"`
+	ToolID           uuid.UUID  `json:"tool_id"`
+	ToolName         string     `json:"tool_name"`
+	SourceToolID     uuid.UUID  `json:"source_tool_id"`
+	SourceToolName   string     `json:"source_tool_name"`
+	SourceServerID   uuid.UUID  `json:"source_server_id"`
+	SourceServerName string     `json:"source_server_name"`
+	Similarity       float64    `json:"similarity"`
+	FlaggedAt        time.Time  `json:"flagged_at"`
+	ReviewedAt       *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// insertLSHBuckets stores a tool's LSH buckets so similar tools can be found by index
+func insertLSHBuckets(ctx context.Context, tx pgx.Tx, tool *ToolDefinition) error {
+	buckets := similarity.FromInt64s(tool.MinHash).Buckets()
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	bands := make([]int16, len(buckets))
+	values := make([]int64, len(buckets))
+	for i, bucket := range buckets {
+		bands[i] = int16(i)
+		values[i] = int64(bucket)
+	}
+
+	query := `
+		INSERT INTO tool_lsh_buckets (tool_id, band, bucket)
+		SELECT $1, b.band, b.bucket FROM unnest($2::smallint[], $3::bigint[]) AS b(band, bucket)
+		ON CONFLICT (tool_id, band) DO UPDATE SET bucket = EXCLUDED.bucket
+	`
+	if _, err := tx.Exec(ctx, query, tool.ID, bands, values); err != nil {
+		return fmt.Errorf("insert lsh buckets: %w", err)
+	}
+	return nil
+}
+
+// GetSimilarTools retrieves current tools whose descriptions are near-duplicates of a tool's,
+// most similar first
+func (db *DB) GetSimilarTools(ctx context.Context, toolID uuid.UUID, threshold float64) ([]*SimilarTool, error) {
+	var minhash []int64
+	err := db.pool.QueryRow(ctx, "SELECT minhash FROM tool_definitions WHERE id = $1", toolID).Scan(&minhash)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("tool not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get tool signature: %w", err)
+	}
+	signature := similarity.FromInt64s(minhash)
+	if signature == nil {
+		return make([]*SimilarTool, 0), nil
+	}
+
+	// Candidates share a bucket in at least one band
+	query := `
+		SELECT DISTINCT t.id, t.server_id, s.name, t.tool_name, t.description, t.minhash
+		FROM tool_lsh_buckets mine
+		JOIN tool_lsh_buckets other ON other.band = mine.band AND other.bucket = mine.bucket
+		JOIN tool_definitions t ON t.id = other.tool_id
+		JOIN servers s ON s.id = t.server_id
+		WHERE mine.tool_id = $1 AND other.tool_id <> $1
+		  AND EXISTS (
+			SELECT 1 FROM scan_tools st
+			WHERE st.tool_id = t.id AND st.scan_id = (
+				SELECT id FROM scans
+				WHERE server_id = t.server_id AND ref IS NULL AND tools_recorded
+				ORDER BY scanned_at DESC
+				LIMIT 1
+			)
+		  )
+	`
+
+	rows, err := db.pool.Query(ctx, query, toolID)
+	if err != nil {
+		return nil, fmt.Errorf("query similar tools: %w", err)
+	}
+	defer rows.Close()
+
+	tools := make([]*SimilarTool, 0)
+	for rows.Next() {
+		tool := &SimilarTool{}
+		var candidate []int64
+		if err := rows.Scan(&tool.ToolID, &tool.ServerID, &tool.ServerName, &tool.ToolName, &tool.Description, &candidate); err != nil {
+			return nil, fmt.Errorf("scan similar tool row: %w", err)
+		}
+		tool.Similarity = signature.Similarity(similarity.FromInt64s(candidate))
+		if tool.Similarity >= threshold {
+			tools = append(tools, tool)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate similar tools: %w", err)
+	}
+
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Similarity > tools[j].Similarity })
+	return tools, nil
+}
+
+// FlagSimilarTool marks a tool for review as a near-duplicate of a critical tool
+// Reports whether the flag is new rather than an update of an existing one
+func (db *DB) FlagSimilarTool(ctx context.Context, toolID, sourceToolID uuid.UUID, score float64) (bool, error) {
+	query := `
+		INSERT INTO similarity_flags (tool_id, source_tool_id, similarity)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tool_id, source_tool_id) DO UPDATE SET similarity = EXCLUDED.similarity
+		RETURNING xmax = 0
+	`
+	var created bool
+	err := db.pool.QueryRow(ctx, query, toolID, sourceToolID, score).Scan(&created)
+	if err != nil {
+		return false, fmt.Errorf("flag similar tool: %w", err)
+	}
+	return created, nil
+}
+
+// ReviewSimilarityFlag marks a flag as reviewed, which hides it from the server's page
+func (db *DB) ReviewSimilarityFlag(ctx context.Context, id uuid.UUID) error {
+	tag, err := db.pool.Exec(ctx, `
+		UPDATE similarity_flags SET reviewed_at = NOW()
+		WHERE id = $1 AND reviewed_at IS NULL
+	`, id)
+	if err != nil {
+		return fmt.Errorf("review similarity flag: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("similarity flag %w", ErrNotFound)
+	}
+	return nil
+}
+
+// GetSimilarityFlagsForServer retrieves unreviewed flags on a server's tools
+func (db *DB) GetSimilarityFlagsForServer(ctx context.Context, serverID uuid.UUID) ([]*SimilarityFlag, error) {
+	query := `
+		SELECT f.id, f.tool_id, t.tool_name, f.source_tool_id, src.tool_name, src.server_id, s.name,
+			   f.similarity, f.flagged_at, f.reviewed_at
+		FROM similarity_flags f
+		JOIN tool_definitions t ON t.id = f.tool_id
+		JOIN tool_definitions src ON src.id = f.source_tool_id
+		JOIN servers s ON s.id = src.server_id
+		WHERE t.server_id = $1 AND f.reviewed_at IS NULL
+		ORDER BY f.flagged_at DESC
+	`
+
+	rows, err := db.pool.Query(ctx, query, serverID)
+	if err != nil {
+		return nil, fmt.Errorf("query similarity flags: %w", err)
+	}
+	defer rows.Close()
+
+	flags := make([]*SimilarityFlag, 0)
+	for rows.Next() {
+		flag := &SimilarityFlag{}
+		err := rows.Scan(
+			&flag.ID, &flag.ToolID, &flag.ToolName, &flag.SourceToolID, &flag.SourceToolName,
+			&flag.SourceServerID, &flag.SourceServerName, &flag.Similarity, &flag.FlaggedAt, &flag.ReviewedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan similarity flag row: %w", err)
+		}
+		flags = append(flags, flag)
+	}
+
+	return flags, nil
+}
+
+// EnqueueScan queues a server for scanning ahead of the regular schedule; a higher priority wins
+func (db *DB) EnqueueScan(ctx context.Context, serverID uuid.UUID, priority int) error {
+	query := `
+		INSERT INTO scan_queue (server_id, priority)
+		VALUES ($1, $2)
+		ON CONFLICT (server_id) DO UPDATE SET priority = GREATEST(scan_queue.priority, EXCLUDED.priority)
+	`
+	err := db.Exec(ctx, query, serverID, priority)
+	if err != nil {
+		return fmt.Errorf("enqueue scan: %w", err)
+	}
+	return nil
+}
+
+// DequeueScan removes a server from the scan queue once it has been scanned
+func (db *DB) DequeueScan(ctx context.Context, serverID uuid.UUID) error {
+	err := db.Exec(ctx, "DELETE FROM scan_queue WHERE server_id = $1", serverID)
+	if err != nil {
+		return fmt.Errorf("dequeue scan: %w", err)
+	}
+	return nil
+}
@@ -18,24 +18,24 @@ type ToolDefinition struct {
 	Description *string         `json:"description,omitempty"`
 	Parameters  json.RawMessage `json:"parameters,omitempty"`
 	ContentHash string          `json:"content_hash"`
+	Outputs     json.RawMessage `json:"outputs,omitempty"` // Text the handler returns, [{"text", "line"}]
+	OutputHash  *string         `json:"output_hash,omitempty"`
+	MinHash     []int64         `json:"-"` // Description signature, nil when too short to compare
 	FirstSeen   time.Time       `json:"first_seen"`
 	LastSeen    time.Time       `json:"last_seen"`
 }
 
-// InsertToolDefinitions inserts multiple tool definitions for a server
-func (db *DB) InsertToolDefinitions(ctx context.Context, tools []*ToolDefinition) error {
-	if len(tools) == 0 {
-		return nil
-	}
-
+// InsertToolDefinitions inserts a scan's tool definitions and records them as the scan's snapshot
+func (db *DB) InsertToolDefinitions(ctx context.Context, scanID uuid.UUID, tools []*ToolDefinition) error {
 	return db.WithTransaction(ctx, func(tx pgx.Tx) error {
 		for _, tool := range tools {
 			query := `
 				INSERT INTO tool_definitions (
-					server_id, tool_name, description, parameters, content_hash
-				) VALUES ($1, $2, $3, $4, $5)
+					server_id, tool_name, description, parameters, content_hash, outputs, output_hash, minhash
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 				ON CONFLICT (server_id, tool_name, content_hash)
-				DO UPDATE SET last_seen = NOW()
+				DO UPDATE SET last_seen = NOW(), outputs = EXCLUDED.outputs, output_hash = EXCLUDED.output_hash,
+				              minhash = EXCLUDED.minhash
 				RETURNING id, first_seen, last_seen
 			`
 
@@ -45,30 +45,52 @@ func (db *DB) InsertToolDefinitions(ctx context.Context, tools []*ToolDefinition
 				tool.Description,
 				tool.Parameters,
 				tool.ContentHash,
+				tool.Outputs,
+				tool.OutputHash,
+				tool.MinHash,
 			).Scan(&tool.ID, &tool.FirstSeen, &tool.LastSeen)
 
 			if err != nil {
 				return fmt.Errorf("insert tool definition: %w", err)
 			}
+
+			if err := insertLSHBuckets(ctx, tx, tool); err != nil {
+				return err
+			}
+
+			_, err = tx.Exec(ctx, `
+				INSERT INTO scan_tools (scan_id, tool_id, outputs, output_hash)
+				VALUES ($1, $2, $3, $4)
+				ON CONFLICT (scan_id, tool_id) DO NOTHING
+			`, scanID, tool.ID, tool.Outputs, tool.OutputHash)
+			if err != nil {
+				return fmt.Errorf("insert scan tool: %w", err)
+			}
+		}
+
+		// An empty snapshot is still a snapshot
+		if _, err := tx.Exec(ctx, "UPDATE scans SET tools_recorded = TRUE WHERE id = $1", scanID); err != nil {
+			return fmt.Errorf("mark tools recorded: %w", err)
 		}
 
 		return nil
 	})
 }
 
-// GetToolDefinitionsForServer retrieves all current tool definitions for a server
-func (db *DB) GetToolDefinitionsForServer(ctx context.Context, serverID uuid.UUID) ([]*ToolDefinition, error) {
-	query := `
-		SELECT id, server_id, tool_name, description, parameters, content_hash, first_seen, last_seen
-		FROM tool_definitions
-		WHERE server_id = $1
-		ORDER BY tool_name
-	`
+// snapshotColumns lists the columns of a tool in a scan snapshot, in the order scanToolRows expects
+// Outputs come from the snapshot since they can change without changing the tool's content hash
+const snapshotColumns = `t.id, t.server_id, t.tool_name, t.description, t.parameters, t.content_hash,
+			   st.outputs, st.output_hash, t.first_seen, t.last_seen`
 
-	rows, err := db.pool.Query(ctx, query, serverID)
-	if err != nil {
-		return nil, fmt.Errorf("query tool definitions: %w", err)
-	}
+// latestSnapshotScan selects the latest default-branch scan with a snapshot of server $1
+const latestSnapshotScan = `
+	SELECT id FROM scans
+	WHERE server_id = $1 AND ref IS NULL AND tools_recorded
+	ORDER BY scanned_at DESC
+	LIMIT 1`
+
+// scanToolRows reads the tools selected with snapshotColumns
+func scanToolRows(rows pgx.Rows) ([]*ToolDefinition, error) {
 	defer rows.Close()
 
 	tools := make([]*ToolDefinition, 0)
@@ -77,13 +99,139 @@ func (db *DB) GetToolDefinitionsForServer(ctx context.Context, serverID uuid.UUI
 		err := rows.Scan(
 			&tool.ID, &tool.ServerID, &tool.ToolName,
 			&tool.Description, &tool.Parameters, &tool.ContentHash,
-			&tool.FirstSeen, &tool.LastSeen,
+			&tool.Outputs, &tool.OutputHash, &tool.FirstSeen, &tool.LastSeen,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan tool definition row: %w", err)
 		}
 		tools = append(tools, tool)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tool definitions: %w", err)
+	}
+
+	return tools, nil
+}
+
+// GetToolDefinitionsForServer retrieves a server's current tools, from its latest snapshot
+func (db *DB) GetToolDefinitionsForServer(ctx context.Context, serverID uuid.UUID) ([]*ToolDefinition, error) {
+	query := `
+		SELECT ` + snapshotColumns + `
+		FROM scan_tools st
+		JOIN tool_definitions t ON t.id = st.tool_id
+		WHERE st.scan_id = (` + latestSnapshotScan + `)
+		ORDER BY t.tool_name
+	`
+
+	rows, err := db.pool.Query(ctx, query, serverID)
+	if err != nil {
+		return nil, fmt.Errorf("query tool definitions: %w", err)
+	}
+	return scanToolRows(rows)
+}
+
+// GetKnownToolDefinitions retrieves every tool definition ever stored for a server
+// Outputs are the latest seen with each definition
+func (db *DB) GetKnownToolDefinitions(ctx context.Context, serverID uuid.UUID) ([]*ToolDefinition, error) {
+	query := `
+		SELECT t.id, t.server_id, t.tool_name, t.description, t.parameters, t.content_hash,
+			   t.outputs, t.output_hash, t.first_seen, t.last_seen
+		FROM tool_definitions t
+		WHERE t.server_id = $1
+		ORDER BY t.tool_name, t.last_seen DESC
+	`
+
+	rows, err := db.pool.Query(ctx, query, serverID)
+	if err != nil {
+		return nil, fmt.Errorf("query known tool definitions: %w", err)
+	}
+	return scanToolRows(rows)
+}
+
+// GetToolsForScan retrieves the tools a scan saw
+func (db *DB) GetToolsForScan(ctx context.Context, scanID uuid.UUID) ([]*ToolDefinition, error) {
+	query := `
+		SELECT ` + snapshotColumns + `
+		FROM scan_tools st
+		JOIN tool_definitions t ON t.id = st.tool_id
+		WHERE st.scan_id = $1
+		ORDER BY t.tool_name
+	`
+
+	rows, err := db.pool.Query(ctx, query, scanID)
+	if err != nil {
+		return nil, fmt.Errorf("query scan tools: %w", err)
+	}
+	return scanToolRows(rows)
+}
+
+// GetPreviousToolSnapshot retrieves the default-branch snapshot taken before a scan
+// Returns a nil scan ID when the scan is the server's first snapshot
+func (db *DB) GetPreviousToolSnapshot(ctx context.Context, serverID, scanID uuid.UUID) (*uuid.UUID, []*ToolDefinition, error) {
+	query := `
+		SELECT id FROM scans
+		WHERE server_id = $1 AND ref IS NULL AND tools_recorded AND id <> $2
+		  AND scanned_at <= (SELECT scanned_at FROM scans WHERE id = $2)
+		ORDER BY scanned_at DESC
+		LIMIT 1
+	`
+
+	var previousID uuid.UUID
+	err := db.pool.QueryRow(ctx, query, serverID, scanID).Scan(&previousID)
+	if err == pgx.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("get previous snapshot: %w", err)
+	}
+
+	tools, err := db.GetToolsForScan(ctx, previousID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &previousID, tools, nil
+}
+
+// IndexedTool is one server's current tool in the ecosystem-wide tool index
+type IndexedTool struct {
+	ToolID      uuid.UUID `json:"tool_id"`
+	ServerID    uuid.UUID `json:"server_id"`
+	ServerName  string    `json:"server_name"`
+	Stars       int       `json:"stars"`
+	ToolName    string    `json:"tool_name"`
+	Description *string   `json:"description,omitempty"`
+	MinHash     []int64   `json:"-"`
+}
+
+// GetToolIndex retrieves the tools of every server's latest scan
+func (db *DB) GetToolIndex(ctx context.Context) ([]*IndexedTool, error) {
+	query := `
+		SELECT t.id, t.server_id, s.name, s.stars, t.tool_name, t.description, t.minhash
+		FROM servers s
+		JOIN scan_tools st ON st.scan_id = (
+			SELECT id FROM scans
+			WHERE server_id = s.id AND ref IS NULL AND tools_recorded
+			ORDER BY scanned_at DESC
+			LIMIT 1
+		)
+		JOIN tool_definitions t ON t.id = st.tool_id
+		ORDER BY s.stars DESC, t.server_id, t.tool_name
+	`
+
+	rows, err := db.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query tool index: %w", err)
+	}
+	defer rows.Close()
+
+	tools := make([]*IndexedTool, 0)
+	for rows.Next() {
+		tool := &IndexedTool{}
+		if err := rows.Scan(&tool.ToolID, &tool.ServerID, &tool.ServerName, &tool.Stars, &tool.ToolName, &tool.Description, &tool.MinHash); err != nil {
+			return nil, fmt.Errorf("scan tool index row: %w", err)
+		}
+		tools = append(tools, tool)
+	}
 
 	return tools, nil
 }
@@ -0,0 +1,154 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParamChange is a change to one parameter of a tool's input schema
+type ParamChange struct {
+	Name    string `json:"name"`
+	Change  string `json:"change"` // "added", "removed", "retyped", "now_required", "now_optional" or "description"
+	OldType string `json:"old_type,omitempty"`
+	NewType string `json:"new_type,omitempty"`
+	// Word diff of the parameter's description, set for "description" changes and added parameters
+	Description []Segment `json:"description,omitempty"`
+}
+
+// param is a parameter as read from a schema
+type param struct {
+	Type        string
+	Description string
+	Required    bool
+}
+
+// Schema compares two tool input schemas parameter by parameter
+// Either a JSON Schema object with properties and required, or a map of parameter name to schema
+func Schema(old, new json.RawMessage) ([]ParamChange, error) {
+	oldParams, err := readParams(old)
+	if err != nil {
+		return nil, fmt.Errorf("read old parameters: %w", err)
+	}
+	newParams, err := readParams(new)
+	if err != nil {
+		return nil, fmt.Errorf("read new parameters: %w", err)
+	}
+
+	names := make([]string, 0, len(oldParams)+len(newParams))
+	for name := range oldParams {
+		names = append(names, name)
+	}
+	for name := range newParams {
+		if _, ok := oldParams[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := make([]ParamChange, 0)
+	for _, name := range names {
+		before, hadBefore := oldParams[name]
+		after, hasAfter := newParams[name]
+
+		switch {
+		case !hasAfter:
+			changes = append(changes, ParamChange{Name: name, Change: "removed", OldType: before.Type})
+		case !hadBefore:
+			change := ParamChange{Name: name, Change: "added", NewType: after.Type}
+			if after.Description != "" {
+				change.Description = []Segment{{Op: Insert, Text: after.Description}}
+			}
+			changes = append(changes, change)
+		default:
+			if before.Type != after.Type {
+				changes = append(changes, ParamChange{Name: name, Change: "retyped", OldType: before.Type, NewType: after.Type})
+			}
+			if before.Required != after.Required {
+				change := "now_optional"
+				if after.Required {
+					change = "now_required"
+				}
+				changes = append(changes, ParamChange{Name: name, Change: change, NewType: after.Type})
+			}
+			if before.Description != after.Description {
+				changes = append(changes, ParamChange{
+					Name:        name,
+					Change:      "description",
+					NewType:     after.Type,
+					Description: Words(before.Description, after.Description),
+				})
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// readParams reads the parameters of a schema
+func readParams(raw json.RawMessage) (map[string]param, error) {
+	params := make(map[string]param)
+	if len(raw) == 0 || string(raw) == "null" {
+		return params, nil
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, err
+	}
+
+	properties := schema
+	required := make(map[string]bool)
+	if props, ok := schema["properties"].(map[string]interface{}); ok {
+		properties = props
+		if names, ok := schema["required"].([]interface{}); ok {
+			for _, name := range names {
+				if s, ok := name.(string); ok {
+					required[s] = true
+				}
+			}
+		}
+	}
+
+	for name, value := range properties {
+		p := param{Required: required[name]}
+		switch v := value.(type) {
+		case string:
+			p.Type = v
+		case map[string]interface{}:
+			p.Type = schemaType(v)
+			p.Description, _ = v["description"].(string)
+		default:
+			p.Type = "any"
+		}
+		params[name] = p
+	}
+
+	return params, nil
+}
+
+// schemaType describes the type of a property schema
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		if t == "array" {
+			if items, ok := schema["items"].(map[string]interface{}); ok {
+				return "array<" + schemaType(items) + ">"
+			}
+		}
+		return t
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return strings.Join(types, "|")
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		return ref
+	}
+	return "any"
+}
@@ -0,0 +1,169 @@
+package diff
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Op is what happened to a segment of text
+type Op string
+
+// Segment operations
+const (
+	Equal  Op = "equal"
+	Insert Op = "insert"
+	Delete Op = "delete"
+)
+
+// Segment is a run of text with the same operation
+type Segment struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// maxCells bounds the LCS table; larger changes are reported as a whole replacement
+const maxCells = 4 << 20
+
+// tokenPattern splits text into words, punctuation and whitespace, so segments rejoin exactly
+// and a word doesn't show as changed when only the punctuation after it did
+var tokenPattern = regexp.MustCompile(`\s+|[\p{L}\p{N}_]+|[^\s\p{L}\p{N}_]`)
+
+// Words returns a word-level diff of two texts
+// Concatenating the equal and delete segments gives old; equal and insert segments give new
+func Words(old, new string) []Segment {
+	a := tokenPattern.FindAllString(old, -1)
+	b := tokenPattern.FindAllString(new, -1)
+
+	// Common prefix and suffix don't need the table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	segments := make([]Segment, 0)
+	segments = appendTokens(segments, Equal, a[:prefix])
+	segments = append(segments, middle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	segments = appendTokens(segments, Equal, a[len(a)-suffix:])
+
+	return merge(segments)
+}
+
+// middle diffs the tokens between the common prefix and suffix by longest common subsequence
+func middle(a, b []string) []Segment {
+	segments := make([]Segment, 0)
+	if len(a) == 0 || len(b) == 0 || len(a)*len(b) > maxCells {
+		segments = appendTokens(segments, Delete, a)
+		return appendTokens(segments, Insert, b)
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			segments = append(segments, Segment{Op: Equal, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			segments = append(segments, Segment{Op: Delete, Text: a[i]})
+			i++
+		default:
+			segments = append(segments, Segment{Op: Insert, Text: b[j]})
+			j++
+		}
+	}
+	segments = appendTokens(segments, Delete, a[i:])
+	return appendTokens(segments, Insert, b[j:])
+}
+
+// appendTokens appends one segment per token
+func appendTokens(segments []Segment, op Op, tokens []string) []Segment {
+	for _, token := range tokens {
+		segments = append(segments, Segment{Op: op, Text: token})
+	}
+	return segments
+}
+
+// merge joins adjacent segments with the same operation, and keeps whitespace between two
+// changed words in the change rather than as a one-space equal segment
+func merge(segments []Segment) []Segment {
+	for i := 1; i+1 < len(segments); i++ {
+		if segments[i].Op == Equal && strings.TrimSpace(segments[i].Text) == "" &&
+			segments[i-1].Op != Equal && segments[i+1].Op != Equal {
+			segments = append(segments[:i], append([]Segment{
+				{Op: Delete, Text: segments[i].Text},
+				{Op: Insert, Text: segments[i].Text},
+			}, segments[i+1:]...)...)
+			i++
+		}
+	}
+
+	// Deletions go before insertions within a changed run, so the run reads as old then new
+	merged := make([]Segment, 0, len(segments))
+	for start := 0; start < len(segments); {
+		if segments[start].Op == Equal {
+			merged = appendMerged(merged, segments[start])
+			start++
+			continue
+		}
+		end := start
+		var deleted, inserted strings.Builder
+		for end < len(segments) && segments[end].Op != Equal {
+			if segments[end].Op == Delete {
+				deleted.WriteString(segments[end].Text)
+			} else {
+				inserted.WriteString(segments[end].Text)
+			}
+			end++
+		}
+		if deleted.Len() > 0 {
+			merged = append(merged, Segment{Op: Delete, Text: deleted.String()})
+		}
+		if inserted.Len() > 0 {
+			merged = append(merged, Segment{Op: Insert, Text: inserted.String()})
+		}
+		start = end
+	}
+
+	return merged
+}
+
+// appendMerged appends a segment, joining it to the last one if they share an operation
+func appendMerged(segments []Segment, segment Segment) []Segment {
+	if n := len(segments); n > 0 && segments[n-1].Op == segment.Op {
+		segments[n-1].Text += segment.Text
+		return segments
+	}
+	return append(segments, segment)
+}
+
+// Inserted returns the inserted text, one line per inserted run
+func Inserted(segments []Segment) string {
+	runs := make([]string, 0)
+	for _, segment := range segments {
+		if segment.Op == Insert {
+			if text := strings.TrimSpace(segment.Text); text != "" {
+				runs = append(runs, text)
+			}
+		}
+	}
+	return strings.Join(runs, "\n")
+}
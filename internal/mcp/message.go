@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// ProtocolVersion is the MCP revision mcpsek speaks when it serves
+const ProtocolVersion = "2025-06-18"
+
+// JSON-RPC error codes
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	CodeBlocked        = -32001 // The proxy refused the request or response by policy
+)
+
+// Message is a JSON-RPC 2.0 request, notification or response
+type Message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC error object
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// IsRequest reports whether the message is a request that expects a response
+func (m *Message) IsRequest() bool {
+	return m.Method != "" && len(m.ID) > 0
+}
+
+// IsNotification reports whether the message is a notification
+func (m *Message) IsNotification() bool {
+	return m.Method != "" && len(m.ID) == 0
+}
+
+// IsResponse reports whether the message is a result or error response
+func (m *Message) IsResponse() bool {
+	return m.Method == "" && len(m.ID) > 0
+}
+
+// Key identifies a request's ID across both directions: JSON-RPC IDs may be numbers or strings
+func (m *Message) Key() string {
+	return string(bytes.TrimSpace(m.ID))
+}
+
+// NewResult builds the response to a request
+func NewResult(id json.RawMessage, result interface{}) (*Message, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("marshal result: %w", err)
+	}
+	return &Message{JSONRPC: "2.0", ID: id, Result: data}, nil
+}
+
+// NewError builds an error response to a request
+func NewError(id json.RawMessage, code int, message string) *Message {
+	return &Message{JSONRPC: "2.0", ID: id, Error: &Error{Code: code, Message: message}}
+}
+
+// NewNotification builds a notification without params
+func NewNotification(method string) *Message {
+	return &Message{JSONRPC: "2.0", Method: method}
+}
+
+// Parse reads one message or a batch; batch reports whether the payload was an array
+func Parse(data []byte) ([]*Message, bool, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var messages []*Message
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, true, fmt.Errorf("parse JSON-RPC batch: %w", err)
+		}
+		return messages, true, nil
+	}
+
+	msg := &Message{}
+	if err := json.Unmarshal(data, msg); err != nil {
+		return nil, false, fmt.Errorf("parse JSON-RPC message: %w", err)
+	}
+	return []*Message{msg}, false, nil
+}
+
+// Encode writes messages back as one message or a batch
+func Encode(messages []*Message, batch bool) ([]byte, error) {
+	if batch || len(messages) != 1 {
+		return json.Marshal(messages)
+	}
+	return json.Marshal(messages[0])
+}
@@ -0,0 +1,313 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// supportedVersions are the protocol revisions the server accepts from a client
+var supportedVersions = map[string]bool{
+	"2025-06-18": true,
+	"2025-03-26": true,
+	"2024-11-05": true,
+}
+
+// maxRequestBody bounds an HTTP request to the server
+const maxRequestBody = 4 << 20
+
+// ToolHandler runs a tool call with its raw arguments
+// An error is reported to the model as a tool result with isError set
+type ToolHandler func(ctx context.Context, args json.RawMessage) (*CallToolResult, error)
+
+// PromptHandler renders a prompt with its arguments
+type PromptHandler func(ctx context.Context, args map[string]string) (*GetPromptResult, error)
+
+// Server is a minimal MCP server for tools and prompts over stdio or Streamable HTTP
+type Server struct {
+	name         string
+	version      string
+	instructions string
+
+	// StreamResponses answers HTTP requests with an event stream rather than a JSON body
+	StreamResponses bool
+
+	mu             sync.RWMutex
+	tools          []Tool
+	toolHandlers   map[string]ToolHandler
+	prompts        []Prompt
+	promptHandlers map[string]PromptHandler
+	notify         func(*Message) // Set while serving stdio, where the server can push notifications
+}
+
+// NewServer creates a server that introduces itself with name, version and instructions
+func NewServer(name, version, instructions string) *Server {
+	return &Server{
+		name:           name,
+		version:        version,
+		instructions:   instructions,
+		tools:          make([]Tool, 0),
+		toolHandlers:   make(map[string]ToolHandler),
+		prompts:        make([]Prompt, 0),
+		promptHandlers: make(map[string]PromptHandler),
+	}
+}
+
+// AddTool registers a tool, replacing the definition and handler of one with the same name
+// Clients connected over stdio are told the list changed
+func (s *Server) AddTool(tool Tool, handler ToolHandler) {
+	if len(tool.InputSchema) == 0 {
+		tool.InputSchema = json.RawMessage(`{"type":"object"}`)
+	}
+
+	s.mu.Lock()
+	replaced := false
+	for i := range s.tools {
+		if s.tools[i].Name == tool.Name {
+			s.tools[i] = tool
+			replaced = true
+		}
+	}
+	if !replaced {
+		s.tools = append(s.tools, tool)
+	}
+	s.toolHandlers[tool.Name] = handler
+	notify := s.notify
+	s.mu.Unlock()
+
+	if notify != nil {
+		notify(NewNotification("notifications/tools/list_changed"))
+	}
+}
+
+// AddPrompt registers a prompt
+func (s *Server) AddPrompt(prompt Prompt, handler PromptHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prompts = append(s.prompts, prompt)
+	s.promptHandlers[prompt.Name] = handler
+}
+
+// Tools returns the registered tool definitions
+func (s *Server) Tools() []Tool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]Tool(nil), s.tools...)
+}
+
+// Handle answers one message; notifications and responses get no answer
+func (s *Server) Handle(ctx context.Context, msg *Message) *Message {
+	if !msg.IsRequest() {
+		return nil
+	}
+
+	result, rpcErr := s.dispatch(ctx, msg)
+	if rpcErr != nil {
+		return &Message{JSONRPC: "2.0", ID: msg.ID, Error: rpcErr}
+	}
+	response, err := NewResult(msg.ID, result)
+	if err != nil {
+		return NewError(msg.ID, CodeInternalError, err.Error())
+	}
+	return response
+}
+
+// dispatch runs a request by method
+func (s *Server) dispatch(ctx context.Context, msg *Message) (interface{}, *Error) {
+	switch msg.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(msg.Params, &params)
+		version := ProtocolVersion
+		if supportedVersions[params.ProtocolVersion] {
+			version = params.ProtocolVersion
+		}
+
+		s.mu.RLock()
+		capabilities := map[string]interface{}{"tools": map[string]bool{"listChanged": true}}
+		if len(s.prompts) > 0 {
+			capabilities["prompts"] = map[string]bool{}
+		}
+		s.mu.RUnlock()
+
+		return map[string]interface{}{
+			"protocolVersion": version,
+			"capabilities":    capabilities,
+			"serverInfo":      map[string]string{"name": s.name, "version": s.version},
+			"instructions":    s.instructions,
+		}, nil
+
+	case "ping":
+		return map[string]interface{}{}, nil
+
+	case "tools/list":
+		return map[string]interface{}{"tools": s.Tools()}, nil
+
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil || params.Name == "" {
+			return nil, &Error{Code: CodeInvalidParams, Message: "tools/call needs a tool name"}
+		}
+		s.mu.RLock()
+		handler, ok := s.toolHandlers[params.Name]
+		s.mu.RUnlock()
+		if !ok {
+			return nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("Unknown tool: %s", params.Name)}
+		}
+		if len(params.Arguments) == 0 || string(params.Arguments) == "null" {
+			params.Arguments = json.RawMessage(`{}`)
+		}
+
+		result, err := handler(ctx, params.Arguments)
+		if err != nil {
+			return ErrorResult(err.Error()), nil
+		}
+		return result, nil
+
+	case "prompts/list":
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return map[string]interface{}{"prompts": s.prompts}, nil
+
+	case "prompts/get":
+		var params struct {
+			Name      string            `json:"name"`
+			Arguments map[string]string `json:"arguments"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil || params.Name == "" {
+			return nil, &Error{Code: CodeInvalidParams, Message: "prompts/get needs a prompt name"}
+		}
+		s.mu.RLock()
+		handler, ok := s.promptHandlers[params.Name]
+		s.mu.RUnlock()
+		if !ok {
+			return nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("Unknown prompt: %s", params.Name)}
+		}
+
+		result, err := handler(ctx, params.Arguments)
+		if err != nil {
+			return nil, &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+		return result, nil
+	}
+
+	return nil, &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("Method not found: %s", msg.Method)}
+}
+
+// handlePayload answers a message or batch, returning the responses to send
+func (s *Server) handlePayload(ctx context.Context, data []byte) ([]*Message, bool) {
+	messages, batch, err := Parse(data)
+	if err != nil {
+		return []*Message{NewError(json.RawMessage("null"), CodeParseError, err.Error())}, false
+	}
+
+	responses := make([]*Message, 0, len(messages))
+	for _, msg := range messages {
+		if response := s.Handle(ctx, msg); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	return responses, batch
+}
+
+// ServeStdio serves newline-delimited messages from in until it closes
+// Requests are answered concurrently, so a slow tool call doesn't hold up a ping
+func (s *Server) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	reader := NewReader(in)
+	writer := NewWriter(out)
+	send := func(messages []*Message, batch bool) {
+		if len(messages) == 0 {
+			return
+		}
+		if data, err := Encode(messages, batch); err == nil {
+			writer.Write(data)
+		}
+	}
+
+	s.mu.Lock()
+	s.notify = func(msg *Message) { send([]*Message{msg}, false) }
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.notify = nil
+		s.mu.Unlock()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		line, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("read stdin: %w", err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			send(s.handlePayload(ctx, line))
+		}()
+	}
+}
+
+// ServeHTTP serves the Streamable HTTP transport: each POST carries messages and gets their
+// responses back; the server pushes nothing unprompted, so there's no GET stream
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// Browsers send Origin; a page on another host mustn't reach a server bound to localhost
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+			http.Error(w, "Origin not allowed", http.StatusForbidden)
+			return
+		}
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	if err != nil {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	responses, batch := s.handlePayload(r.Context(), data)
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if s.StreamResponses {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		for _, response := range responses {
+			data, _ := json.Marshal(response)
+			WriteEvent(w, Event{Event: "message", Data: string(data)})
+		}
+		return
+	}
+
+	body, err := Encode(responses, batch)
+	if err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
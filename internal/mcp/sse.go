@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Event is one server-sent event of a Streamable HTTP response
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+	Retry string
+}
+
+// ReadEvents calls fn for each event in an SSE stream until it ends or fn fails
+func ReadEvents(r io.Reader, fn func(Event) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+
+	var ev Event
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 && ev.ID == "" && ev.Retry == "" {
+			return nil
+		}
+		ev.Data = strings.Join(data, "\n")
+		err := fn(ev)
+		ev, data = Event{}, nil
+		return err
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			if err := dispatch(); err != nil {
+				return err
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // Comment, often a keep-alive
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			ev.ID = value
+		case "event":
+			ev.Event = value
+		case "data":
+			data = append(data, value)
+		case "retry":
+			ev.Retry = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read event stream: %w", err)
+	}
+
+	return dispatch()
+}
+
+// WriteEvent writes one event to an SSE stream
+func WriteEvent(w io.Writer, ev Event) error {
+	var b strings.Builder
+	if ev.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", ev.ID)
+	}
+	if ev.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", ev.Event)
+	}
+	if ev.Retry != "" {
+		fmt.Fprintf(&b, "retry: %s\n", ev.Retry)
+	}
+	// Events without data, such as the ID-only event that primes resumption, stay without it
+	if ev.Data != "" {
+		for _, line := range strings.Split(ev.Data, "\n") {
+			fmt.Fprintf(&b, "data: %s\n", line)
+		}
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
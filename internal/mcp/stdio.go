@@ -0,0 +1,56 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Reader reads newline-delimited messages from the stdio transport
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader wraps r in a message reader
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReaderSize(r, 64*1024)}
+}
+
+// Read returns the next non-empty line without its newline
+// Lines aren't length-limited: a tools/list response can be large
+func (r *Reader) Read() ([]byte, error) {
+	for {
+		line, err := r.r.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			return line, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Writer writes newline-delimited messages, safe for use by several goroutines
+type Writer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriter wraps w in a message writer
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write writes one message followed by a newline
+func (w *Writer) Write(data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	line := make([]byte, 0, len(data)+1)
+	line = append(line, data...)
+	line = append(line, '\n')
+	_, err := w.w.Write(line)
+	return err
+}
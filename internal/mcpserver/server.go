@@ -0,0 +1,115 @@
+package mcpserver
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mcpsek/mcpsek/internal/classifier"
+	"github.com/mcpsek/mcpsek/internal/database"
+	"github.com/mcpsek/mcpsek/internal/mcp"
+	"github.com/mcpsek/mcpsek/internal/scanner"
+)
+
+// Version is reported to clients in serverInfo
+const Version = "1.0.0"
+
+// instructions are shown to the client's model when it connects
+const instructions = "mcpsek scans public MCP servers for poisoned tools, weak authentication and exposed endpoints. " +
+	"Look a server up before suggesting an install, and check tool definitions you're unsure of."
+
+// Handler serves mcpsek's index as MCP tools, backed by the same database layer as the REST API
+type Handler struct {
+	db        *database.DB
+	model     *classifier.Model
+	threshold float64
+}
+
+// New creates the MCP server with mcpsek's tools; model may be nil to skip the classifier
+// It refuses to start if any of its own tool definitions fail the integrity checks it serves
+func New(db *database.DB, model *classifier.Model, threshold float64) (*mcp.Server, error) {
+	h := &Handler{db: db, model: model, threshold: threshold}
+
+	server := mcp.NewServer("mcpsek", Version, instructions)
+	server.AddTool(mcp.Tool{
+		Name:  "lookup_server",
+		Title: "Look up an MCP server",
+		Description: "Look up an MCP server in the mcpsek index by package name, repository URL, name or ID. " +
+			"Returns its trust score (0-100), the status of the tool integrity, authentication and exposure checks, " +
+			"open findings and recent changes to its tool definitions.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"server": {"type": "string", "description": "Package name such as @modelcontextprotocol/server-github, repository URL, server name or mcpsek server ID"},
+				"registry": {"type": "string", "enum": ["npm", "pypi"], "description": "Only match packages from this registry"}
+			},
+			"required": ["server"]
+		}`),
+	}, h.lookupServer)
+
+	server.AddTool(mcp.Tool{
+		Name:  "check_tool_description",
+		Title: "Check a tool definition",
+		Description: "Run the mcpsek tool integrity rules over a tool definition, such as one an MCP server advertises. " +
+			"Reports each rule that matched with its severity, and the hash mcpsek tracks the definition by.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"name": {"type": "string", "description": "Tool name"},
+				"description": {"type": "string", "description": "Tool description as the server lists it"},
+				"input_schema": {"type": "object", "description": "JSON Schema of the tool arguments; parameter names and descriptions are checked too"}
+			},
+			"required": ["name", "description"]
+		}`),
+	}, h.checkToolDescription)
+
+	server.AddTool(mcp.Tool{
+		Name:  "get_recent_mutations",
+		Title: "Recent tool definition changes",
+		Description: "List changes to MCP server tool definitions detected between scans, newest first, " +
+			"with the severity of each change and the integrity rules its added text matched. Give a server to list only its changes.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"server": {"type": "string", "description": "Package name, repository URL, server name or mcpsek server ID"},
+				"registry": {"type": "string", "enum": ["npm", "pypi"], "description": "Only match packages from this registry"},
+				"limit": {"type": "integer", "minimum": 1, "maximum": 100, "default": 20, "description": "Most changes to return"}
+			}
+		}`),
+	}, h.getRecentMutations)
+
+	server.AddTool(mcp.Tool{
+		Name:  "compare_servers",
+		Title: "Compare MCP servers",
+		Description: "Compare two to five MCP servers side by side: trust score, check statuses, open findings " +
+			"and whether their tool definitions can change at runtime, ranked by trust score.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"servers": {"type": "array", "items": {"type": "string"}, "minItems": 2, "maxItems": 5, "description": "Package names, repository URLs, server names or mcpsek server IDs"},
+				"registry": {"type": "string", "enum": ["npm", "pypi"], "description": "Only match packages from this registry"}
+			},
+			"required": ["servers"]
+		}`),
+	}, h.compareServers)
+
+	if err := SelfCheck(server.Tools(), model, threshold); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// SelfCheck runs the integrity checks over tool definitions and fails on any finding
+func SelfCheck(tools []mcp.Tool, model *classifier.Model, threshold float64) error {
+	for _, tool := range tools {
+		findings := scanner.CheckObservedTool(scanner.ObservedTool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: tool.InputSchema,
+		}, model, threshold)
+		if len(findings) > 0 {
+			return fmt.Errorf("tool %s fails integrity check %s (%s): %s",
+				tool.Name, findings[0].PatternMatched, findings[0].Severity, findings[0].Snippet)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,331 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mcpsek/mcpsek/internal/database"
+	"github.com/mcpsek/mcpsek/internal/mcp"
+	"github.com/mcpsek/mcpsek/internal/scanner"
+)
+
+// Limits on what one call returns
+const (
+	maxFindings     = 20
+	maxMutations    = 100
+	defaultLimit    = 20
+	serverMutations = 5 // Recent mutations included in lookup_server
+	minCompared     = 2
+	maxCompared     = 5
+)
+
+// serverSummary is what the tools report about one server
+type serverSummary struct {
+	ID              uuid.UUID         `json:"id"`
+	Name            string            `json:"name"`
+	SourceURL       string            `json:"source_url"`
+	Registry        *string           `json:"registry,omitempty"`
+	Package         *string           `json:"package,omitempty"`
+	Description     string            `json:"description,omitempty"`
+	TrustScore      int               `json:"trust_score"`
+	Assessment      string            `json:"assessment"`       // Worst check status: "pass", "warning" or "critical"; "not_scanned" before the first scan
+	Checks          map[string]string `json:"checks,omitempty"` // Status per check
+	OpenFindings    map[string]int    `json:"open_findings"`    // Count per severity
+	ToolsCount      int               `json:"tools_count"`
+	Transports      []string          `json:"transports,omitempty"`
+	MutationRisk    *string           `json:"mutation_risk,omitempty"` // "runtime" means definitions can change without a release
+	InstallWarnings []string          `json:"install_warnings,omitempty"`
+	LastScanned     *time.Time        `json:"last_scanned,omitempty"`
+	ScannedCommit   string            `json:"scanned_commit,omitempty"`
+	ScannedVersion  *string           `json:"scanned_version,omitempty"`
+	URL             string            `json:"url"` // API resource with the full details
+}
+
+// findingSummary is an open finding; matched text is left out, since it's written to instruct models
+type findingSummary struct {
+	Check    string    `json:"check"`
+	Rule     string    `json:"rule"`
+	Severity string    `json:"severity"`
+	ToolName *string   `json:"tool_name,omitempty"`
+	FilePath *string   `json:"file_path,omitempty"`
+	Line     *int      `json:"line,omitempty"`
+	Message  string    `json:"message,omitempty"`
+	OpenedAt time.Time `json:"opened_at"`
+}
+
+// mutationSummary is a change to a tool definition, without the old and new text
+type mutationSummary struct {
+	ID           uuid.UUID `json:"id"`
+	ServerID     uuid.UUID `json:"server_id"`
+	ToolName     string    `json:"tool_name"`
+	Kind         string    `json:"kind"`
+	Severity     string    `json:"severity"`
+	Reason       *string   `json:"reason,omitempty"`
+	AddedMatches []string  `json:"added_matches,omitempty"` // Integrity rules the added text matched
+	DetectedAt   time.Time `json:"detected_at"`
+}
+
+// lookupServer handles lookup_server
+func (h *Handler) lookupServer(ctx context.Context, args json.RawMessage) (*mcp.CallToolResult, error) {
+	var params struct {
+		Server   string `json:"server"`
+		Registry string `json:"registry"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil || params.Server == "" {
+		return nil, fmt.Errorf("server is required")
+	}
+
+	server, err := h.db.FindServer(ctx, params.Server, params.Registry)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not in the mcpsek index", params.Server)
+	}
+	summary, err := h.summarize(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+
+	findings, _, err := h.db.GetFindingsForServer(ctx, server.ID, database.FindingOpen, maxFindings, 0)
+	if err != nil {
+		return nil, err
+	}
+	mutations, _, err := h.db.GetMutationsForServer(ctx, server.ID, serverMutations, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonResult(map[string]interface{}{
+		"server":           summary,
+		"findings":         summarizeFindings(findings),
+		"recent_mutations": summarizeMutations(mutations),
+	})
+}
+
+// checkToolDescription handles check_tool_description
+func (h *Handler) checkToolDescription(ctx context.Context, args json.RawMessage) (*mcp.CallToolResult, error) {
+	var params struct {
+		Name        string          `json:"name"`
+		Description string          `json:"description"`
+		InputSchema json.RawMessage `json:"input_schema"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil || params.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	findings := scanner.CheckObservedTool(scanner.ObservedTool{
+		Name:        params.Name,
+		Description: params.Description,
+		InputSchema: params.InputSchema,
+	}, h.model, h.threshold)
+
+	status := "pass"
+	matches := make([]map[string]string, 0, len(findings))
+	for _, f := range findings {
+		if status != "critical" {
+			status = f.Severity
+		}
+		// The caller sent the text, so the matched snippet tells them nothing new and isn't echoed
+		matches = append(matches, map[string]string{"rule": f.PatternMatched, "severity": f.Severity})
+	}
+
+	return jsonResult(map[string]interface{}{
+		"status":   status,
+		"hash":     scanner.HashTool(params.Name, params.Description),
+		"findings": matches,
+	})
+}
+
+// getRecentMutations handles get_recent_mutations
+func (h *Handler) getRecentMutations(ctx context.Context, args json.RawMessage) (*mcp.CallToolResult, error) {
+	var params struct {
+		Server   string `json:"server"`
+		Registry string `json:"registry"`
+		Limit    int    `json:"limit"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if params.Limit <= 0 {
+		params.Limit = defaultLimit
+	}
+	if params.Limit > maxMutations {
+		params.Limit = maxMutations
+	}
+
+	var mutations []*database.Mutation
+	if params.Server != "" {
+		server, err := h.db.FindServer(ctx, params.Server, params.Registry)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not in the mcpsek index", params.Server)
+		}
+		if mutations, _, err = h.db.GetMutationsForServer(ctx, server.ID, params.Limit, 0); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if mutations, err = h.db.GetRecentMutations(ctx, params.Limit); err != nil {
+			return nil, err
+		}
+	}
+
+	return jsonResult(map[string]interface{}{"mutations": summarizeMutations(mutations)})
+}
+
+// compareServers handles compare_servers
+func (h *Handler) compareServers(ctx context.Context, args json.RawMessage) (*mcp.CallToolResult, error) {
+	var params struct {
+		Servers  []string `json:"servers"`
+		Registry string   `json:"registry"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if len(params.Servers) < minCompared || len(params.Servers) > maxCompared {
+		return nil, fmt.Errorf("give between %d and %d servers", minCompared, maxCompared)
+	}
+
+	summaries := make([]*serverSummary, 0, len(params.Servers))
+	notFound := make([]string, 0)
+	for _, identifier := range params.Servers {
+		server, err := h.db.FindServer(ctx, identifier, params.Registry)
+		if err != nil {
+			notFound = append(notFound, identifier)
+			continue
+		}
+		summary, err := h.summarize(ctx, server)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+
+	// Unscanned servers have no score worth ranking, so they go last
+	sort.SliceStable(summaries, func(i, j int) bool {
+		scannedI, scannedJ := summaries[i].Assessment != "not_scanned", summaries[j].Assessment != "not_scanned"
+		if scannedI != scannedJ {
+			return scannedI
+		}
+		return summaries[i].TrustScore > summaries[j].TrustScore
+	})
+
+	return jsonResult(map[string]interface{}{
+		"servers":   summaries,
+		"not_found": notFound,
+	})
+}
+
+// summarize builds the summary of a server from its latest default-branch scan
+func (h *Handler) summarize(ctx context.Context, server *database.Server) (*serverSummary, error) {
+	summary := &serverSummary{
+		ID:              server.ID,
+		Name:            server.Name,
+		SourceURL:       server.SourceURL,
+		Registry:        server.PackageRegistry,
+		Package:         server.PackageName,
+		TrustScore:      server.TrustScore,
+		Assessment:      "not_scanned",
+		ToolsCount:      server.ToolsCount,
+		Transports:      server.Transports,
+		MutationRisk:    server.MutationRisk,
+		InstallWarnings: server.InstallWarnings,
+		LastScanned:     server.LastScanned,
+		URL:             "/api/v1/servers/" + server.ID.String(),
+	}
+	// Registry descriptions are written by the publisher; one that would instruct a model is withheld
+	if server.Description != nil && len(scanner.CheckText(server.Name, *server.Description, h.model, h.threshold)) == 0 {
+		summary.Description = *server.Description
+	}
+
+	counts, err := h.db.CountOpenFindings(ctx, server.ID)
+	if err != nil {
+		return nil, err
+	}
+	summary.OpenFindings = counts
+
+	scan, err := h.db.GetLatestScanForServer(ctx, server.ID)
+	if err != nil {
+		return nil, err
+	}
+	if scan == nil {
+		return summary, nil
+	}
+
+	summary.Checks = map[string]string{
+		"tool_integrity": scan.ToolIntegrityStatus,
+		"auth":           scan.AuthStatus,
+		"exposure":       scan.ExposureStatus,
+	}
+	summary.Assessment = "pass"
+	for _, status := range summary.Checks {
+		if status == "critical" || (status == "warning" && summary.Assessment == "pass") {
+			summary.Assessment = status
+		}
+	}
+	summary.ScannedCommit = scan.ShortCommit()
+	summary.ScannedVersion = scan.PackageVersion
+
+	return summary, nil
+}
+
+// summarizeFindings drops the matched text from tool integrity findings
+func summarizeFindings(findings []*database.Finding) []findingSummary {
+	summaries := make([]findingSummary, len(findings))
+	for i, f := range findings {
+		summaries[i] = findingSummary{
+			Check:    f.Check,
+			Rule:     f.Rule,
+			Severity: f.Severity,
+			ToolName: f.ToolName,
+			FilePath: f.FilePath,
+			Line:     f.Line,
+			OpenedAt: f.OpenedAt,
+		}
+		if f.Check != "tool_integrity" {
+			summaries[i].Message = f.Message
+		}
+	}
+	return summaries
+}
+
+// summarizeMutations reports mutations by the rules their added text matched, not the text itself
+func summarizeMutations(mutations []*database.Mutation) []mutationSummary {
+	summaries := make([]mutationSummary, len(mutations))
+	for i, m := range mutations {
+		summaries[i] = mutationSummary{
+			ID:         m.ID,
+			ServerID:   m.ServerID,
+			ToolName:   m.ToolName,
+			Kind:       m.Kind,
+			Severity:   m.Severity,
+			Reason:     m.SeverityReason,
+			DetectedAt: m.DetectedAt,
+		}
+
+		var d scanner.MutationDiff
+		if len(m.Diff) > 0 && json.Unmarshal(m.Diff, &d) == nil {
+			seen := make(map[string]bool)
+			for _, f := range d.Findings {
+				if !seen[f.PatternMatched] {
+					seen[f.PatternMatched] = true
+					summaries[i].AddedMatches = append(summaries[i].AddedMatches, f.PatternMatched)
+				}
+			}
+		}
+	}
+	return summaries
+}
+
+// jsonResult returns data as indented JSON text, and as structured content for clients that read it
+func jsonResult(data interface{}) (*mcp.CallToolResult, error) {
+	text, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal result: %w", err)
+	}
+	result := mcp.TextResult(strings.TrimSpace(string(text)))
+	result.StructuredContent = data
+	return result, nil
+}
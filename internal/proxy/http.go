@@ -0,0 +1,207 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mcpsek/mcpsek/internal/mcp"
+)
+
+// maxProxyBody bounds a request or JSON response body the proxy reads to inspect
+const maxProxyBody = 16 << 20
+
+// hopHeaders are per-connection headers a proxy doesn't forward
+var hopHeaders = map[string]bool{
+	"Connection":        true,
+	"Keep-Alive":        true,
+	"Proxy-Connection":  true,
+	"Te":                true,
+	"Trailer":           true,
+	"Transfer-Encoding": true,
+	"Upgrade":           true,
+	"Host":              true,
+	"Content-Length":    true,
+	"Accept-Encoding":   true, // Left to the transport, so bodies arrive decompressed for inspection
+}
+
+// sessionHeader carries the Streamable HTTP session ID the server assigns at initialization
+const sessionHeader = "Mcp-Session-Id"
+
+// httpProxy proxies the Streamable HTTP transport to one upstream endpoint
+type httpProxy struct {
+	gateway  *Gateway // Requests without a session ID, such as initialize and stateless servers
+	upstream string
+	client   *http.Client
+
+	mu       sync.Mutex
+	sessions map[string]*Gateway // By Mcp-Session-Id; JSON-RPC IDs and tool state are per session
+}
+
+// HTTPHandler returns a handler that proxies the Streamable HTTP transport to the upstream MCP
+// endpoint, inspecting JSON and event-stream responses alike
+func (g *Gateway) HTTPHandler(upstream string) http.Handler {
+	// No client timeout: GET streams stay open as long as the session
+	return &httpProxy{gateway: g, upstream: upstream, client: &http.Client{}, sessions: make(map[string]*Gateway)}
+}
+
+// session returns the gateway for a session ID, creating it on first use
+func (p *httpProxy) session(id string) *Gateway {
+	if id == "" {
+		return p.gateway
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	g, ok := p.sessions[id]
+	if !ok {
+		g = p.gateway.newSession()
+		p.sessions[id] = g
+	}
+	return g
+}
+
+// endSession forgets a session the client deleted or the server no longer knows
+func (p *httpProxy) endSession(id string) {
+	p.mu.Lock()
+	delete(p.sessions, id)
+	p.mu.Unlock()
+}
+
+// ServeHTTP forwards one request; POST bodies are inspected before they're sent
+func (p *httpProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.Header.Get(sessionHeader)
+	g := p.session(sessionID)
+
+	var body io.Reader
+	var replies []byte
+	if r.Method == http.MethodPost {
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxProxyBody))
+		if err != nil {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		var forward []byte
+		forward, replies = g.fromClient(data)
+		if forward == nil {
+			// Every message was refused; the server never sees them
+			writeJSON(w, replies)
+			return
+		}
+		body = bytes.NewReader(forward)
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, p.upstream, body)
+	if err != nil {
+		http.Error(w, "Bad upstream request", http.StatusBadGateway)
+		return
+	}
+	copyHeaders(req.Header, r.Header)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		log.Printf("Upstream request failed: %v", err)
+		http.Error(w, "Upstream unavailable", http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	if sessionID != "" && (resp.StatusCode == http.StatusNotFound || (r.Method == http.MethodDelete && resp.StatusCode < 300)) {
+		p.endSession(sessionID)
+	}
+
+	mediaType := strings.TrimSpace(strings.Split(resp.Header.Get("Content-Type"), ";")[0])
+	switch {
+	case mediaType == "text/event-stream":
+		relayEvents(r.Context(), g, w, resp, replies)
+	case mediaType == "application/json":
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxProxyBody))
+		if err != nil {
+			http.Error(w, "Failed to read upstream response", http.StatusBadGateway)
+			return
+		}
+		copyHeaders(w.Header(), resp.Header)
+		w.WriteHeader(resp.StatusCode)
+		w.Write(mergePayloads(g.fromServer(r.Context(), data), replies))
+	case replies != nil && resp.StatusCode == http.StatusAccepted:
+		// The server accepted the messages that went through; the refusals still need answering
+		copyHeaders(w.Header(), resp.Header)
+		writeJSON(w, replies)
+	default:
+		copyHeaders(w.Header(), resp.Header)
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}
+}
+
+// relayEvents passes an event stream through, inspecting the message in each event
+func relayEvents(ctx context.Context, g *Gateway, w http.ResponseWriter, resp *http.Response, replies []byte) {
+	copyHeaders(w.Header(), resp.Header)
+	w.WriteHeader(resp.StatusCode)
+	flusher, _ := w.(http.Flusher)
+	flush := func() {
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	if replies != nil {
+		mcp.WriteEvent(w, mcp.Event{Event: "message", Data: string(replies)})
+		flush()
+	}
+
+	mcp.ReadEvents(resp.Body, func(ev mcp.Event) error {
+		if ev.Data != "" {
+			ev.Data = string(g.fromServer(ctx, []byte(ev.Data)))
+		}
+		if err := mcp.WriteEvent(w, ev); err != nil {
+			return err
+		}
+		flush()
+		return nil
+	})
+}
+
+// copyHeaders copies end-to-end headers
+func copyHeaders(dst, src http.Header) {
+	for key, values := range src {
+		if hopHeaders[http.CanonicalHeaderKey(key)] {
+			continue
+		}
+		for _, value := range values {
+			dst.Add(key, value)
+		}
+	}
+}
+
+// writeJSON writes the gateway's own replies as a JSON response
+func writeJSON(w http.ResponseWriter, data []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// mergePayloads joins a server payload and the gateway's replies into one batch
+func mergePayloads(payload, replies []byte) []byte {
+	if replies == nil {
+		return payload
+	}
+	messages, _, err := mcp.Parse(payload)
+	if err != nil {
+		return payload
+	}
+	extra, _, err := mcp.Parse(replies)
+	if err != nil {
+		return payload
+	}
+	merged, err := mcp.Encode(append(messages, extra...), true)
+	if err != nil {
+		return payload
+	}
+	return merged
+}
@@ -0,0 +1,64 @@
+package proxy
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/mcpsek/mcpsek/internal/scanner"
+)
+
+// Decision is one logged verdict on a tool, prompt, result or call
+type Decision struct {
+	Time       time.Time      `json:"time"`
+	Method     string         `json:"method"` // JSON-RPC method the decision was made on
+	Kind       string         `json:"kind"`   // "tool", "prompt", "result", "call", "list" or "verify"
+	Name       string         `json:"name"`
+	Action     string         `json:"action"`
+	Reasons    []string       `json:"reasons,omitempty"`
+	Hash       string         `json:"hash,omitempty"`        // Tools only, for pinning
+	HashStatus string         `json:"hash_status,omitempty"` // "matched", "mutated" or "unknown", when a hash check ran
+	Findings   []FindingBrief `json:"findings,omitempty"`
+}
+
+// FindingBrief is the part of an integrity finding worth logging
+type FindingBrief struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Snippet  string `json:"snippet"`
+}
+
+// Logger writes decisions as JSON lines
+type Logger struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewLogger creates a logger writing to w
+func NewLogger(w io.Writer) *Logger {
+	return &Logger{enc: json.NewEncoder(w)}
+}
+
+// Log writes one decision
+func (l *Logger) Log(d Decision) {
+	if d.Time.IsZero() {
+		d.Time = time.Now().UTC()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.enc.Encode(d)
+}
+
+// briefFindings trims findings down for the log
+func briefFindings(findings []scanner.IntegrityFinding) []FindingBrief {
+	if len(findings) == 0 {
+		return nil
+	}
+	briefs := make([]FindingBrief, len(findings))
+	for i, f := range findings {
+		briefs[i] = FindingBrief{Rule: f.PatternMatched, Severity: f.Severity, Snippet: f.Snippet}
+	}
+	return briefs
+}
@@ -0,0 +1,105 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Actions the proxy takes on a tool, prompt or result, from least to most severe
+const (
+	ActionAllow    = "allow"    // Pass it through unchanged
+	ActionAnnotate = "annotate" // Pass it through with a warning the model reads first
+	ActionStrip    = "strip"    // Remove the tool or prompt, or the flagged parts of a result
+	ActionBlock    = "block"    // Refuse the whole list or result; a blocked list cuts off every tool
+)
+
+// actionRank orders actions so the most severe one of several applies
+var actionRank = map[string]int{
+	ActionAllow:    0,
+	ActionAnnotate: 1,
+	ActionStrip:    2,
+	ActionBlock:    3,
+}
+
+// Rules maps what the checks found to the action taken
+type Rules struct {
+	Critical string `json:"critical"` // A critical integrity finding
+	Warning  string `json:"warning"`  // A warning integrity finding
+	Mutated  string `json:"mutated"`  // Tools only: the hash differs from the pinned, scanned or first-seen definition
+	Unknown  string `json:"unknown"`  // Tools only: pins or the mcpsek API don't know the tool
+}
+
+// Policy is the proxy's policy file
+type Policy struct {
+	Tools   Rules `json:"tools"`   // tools/list
+	Prompts Rules `json:"prompts"` // prompts/list
+	Results Rules `json:"results"` // tools/call results
+
+	// Pins maps tool names to the hashes they must have, as logged by the proxy or served by the API
+	Pins map[string]string `json:"pins"`
+
+	// Verify checks tools/list against the latest scan through a mcpsek API
+	Verify *VerifyConfig `json:"verify"`
+
+	Allow []string `json:"allow"` // Tools always allowed, whatever the checks find
+	Deny  []string `json:"deny"`  // Tools always stripped
+}
+
+// VerifyConfig points the proxy at a mcpsek API and the server to verify against
+type VerifyConfig struct {
+	API      string `json:"api"`      // Base URL, e.g. https://mcpsek.example.com/api/v1
+	Server   string `json:"server"`   // Server ID, package name, source URL or name
+	Registry string `json:"registry"` // Optional: "npm" or "pypi"
+}
+
+// DefaultPolicy strips poisoned tools and prompts, annotates warnings, and cuts the server off
+// when a tool changes under the client
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Tools:   Rules{Critical: ActionStrip, Warning: ActionAnnotate, Mutated: ActionBlock, Unknown: ActionAnnotate},
+		Prompts: Rules{Critical: ActionStrip, Warning: ActionAnnotate},
+		Results: Rules{Critical: ActionStrip, Warning: ActionAnnotate},
+	}
+}
+
+// LoadPolicy reads a policy file, or returns the default policy for an empty path
+// Rules the file leaves out keep their defaults
+func LoadPolicy(path string) (*Policy, error) {
+	policy := DefaultPolicy()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read policy: %w", err)
+		}
+		if err := json.Unmarshal(data, policy); err != nil {
+			return nil, fmt.Errorf("parse policy: %w", err)
+		}
+	}
+
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+	if policy.Verify != nil && (policy.Verify.API == "" || policy.Verify.Server == "") {
+		return nil, fmt.Errorf("policy verify needs api and server")
+	}
+
+	return policy, nil
+}
+
+// validate checks every rule names a known action, filling rules left empty with allow
+func (p *Policy) validate() error {
+	for name, rules := range map[string]*Rules{"tools": &p.Tools, "prompts": &p.Prompts, "results": &p.Results} {
+		for field, action := range map[string]*string{
+			"critical": &rules.Critical, "warning": &rules.Warning, "mutated": &rules.Mutated, "unknown": &rules.Unknown,
+		} {
+			if *action == "" {
+				*action = ActionAllow
+			}
+			if _, ok := actionRank[*action]; !ok {
+				return fmt.Errorf("policy %s.%s: unknown action %q", name, field, *action)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/mcpsek/mcpsek/internal/mcp"
+)
+
+// RunStdio launches the server command and proxies the stdio transport between it and the client
+// on in and out; it returns when the server exits, which it does once the client closes in
+func (g *Gateway) RunStdio(ctx context.Context, command []string, in io.Reader, out io.Writer) error {
+	if len(command) == 0 {
+		return fmt.Errorf("no server command")
+	}
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stderr = os.Stderr
+	serverIn, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("server stdin: %w", err)
+	}
+	serverOut, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("server stdout: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start server: %w", err)
+	}
+
+	client := mcp.NewWriter(out)
+	server := mcp.NewWriter(serverIn)
+
+	go func() {
+		// Closing the server's stdin is how the stdio transport tells it to exit
+		defer serverIn.Close()
+		reader := mcp.NewReader(in)
+		for {
+			line, err := reader.Read()
+			if err != nil {
+				return
+			}
+			forward, replies := g.fromClient(line)
+			if forward != nil {
+				if err := server.Write(forward); err != nil {
+					return
+				}
+			}
+			if replies != nil {
+				client.Write(replies)
+			}
+		}
+	}()
+
+	reader := mcp.NewReader(serverOut)
+	for {
+		line, err := reader.Read()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				return fmt.Errorf("read server stdout: %w", err)
+			}
+			break
+		}
+		if err := client.Write(g.fromServer(ctx, line)); err != nil {
+			return fmt.Errorf("write client stdout: %w", err)
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("server exited: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// verifier checks observed tools against the latest scan through a mcpsek API's POST /verify
+type verifier struct {
+	config *VerifyConfig
+	client *http.Client
+}
+
+// newVerifier creates a verifier, or returns nil when the policy has no verify section
+func newVerifier(config *VerifyConfig) *verifier {
+	if config == nil {
+		return nil
+	}
+	return &verifier{config: config, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// verify returns the API's status per tool name
+func (v *verifier) verify(ctx context.Context, tools json.RawMessage) (map[string]string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"server":   v.config.Server,
+		"registry": v.config.Registry,
+		"tools":    tools,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal verify request: %w", err)
+	}
+
+	url := strings.TrimSuffix(v.config.API, "/") + "/verify"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create verify request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("verify request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var payload struct {
+		Data struct {
+			Verdict string `json:"verdict"`
+			Tools   []struct {
+				Name   string `json:"name"`
+				Status string `json:"status"`
+			} `json:"tools"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("decode verify response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("verify %s: %s (HTTP %d)", v.config.Server, payload.Data.Verdict, resp.StatusCode)
+	}
+	if payload.Data.Verdict == "not_scanned" {
+		return nil, fmt.Errorf("verify %s: server has no scan with recorded tools", v.config.Server)
+	}
+
+	statuses := make(map[string]string, len(payload.Data.Tools))
+	for _, tool := range payload.Data.Tools {
+		statuses[tool.Name] = tool.Status
+	}
+	return statuses, nil
+}
@@ -16,18 +16,29 @@ type AuthResult struct {
 	TokenRefresh      *bool           `json:"token_refresh,omitempty"`
 	ScopedPermissions *bool           `json:"scoped_permissions,omitempty"`
 	EnvVarsReferenced []string        `json:"env_vars_referenced,omitempty"`
+	// Requested OAuth scopes compared with tool capabilities, per provider
+	ScopeAnalysis []ProviderScopeAnalysis `json:"scope_analysis,omitempty"`
+	// MCP authorization spec conformance; only evaluated for network transports
+	Conformance *AuthConformance `json:"conformance,omitempty"`
+	// Secrets found in past commits; only populated by deep scans
+	HistorySecrets []HistorySecretFinding `json:"history_secrets,omitempty"`
+	HistoryScan    *HistoryScanInfo       `json:"history_scan,omitempty"`
 }
 
 // SecretFinding represents a committed secret
 type SecretFinding struct {
-	FilePath   string `json:"file_path"`
-	SecretType string `json:"secret_type"`
-	LineNumber int    `json:"line_number"`
-	Snippet    string `json:"snippet"` // Redacted
+	FilePath   string  `json:"file_path"`
+	SecretType string  `json:"secret_type"`
+	Provider   string  `json:"provider,omitempty"`
+	LineNumber int     `json:"line_number"`
+	Snippet    string  `json:"snippet"`             // Redacted to a short prefix
+	Validated  bool    `json:"validated,omitempty"` // Embedded checksum verified offline
+	Entropy    float64 `json:"entropy,omitempty"`   // Set for generic high-entropy matches
+	FileContext
 }
 
 // CheckAuth scans a repository for authentication posture
-func CheckAuth(repoPath string) (*AuthResult, error) {
+func CheckAuth(rc *RepoContext) (*AuthResult, error) {
 	result := &AuthResult{
 		Status:            "pass",
 		Method:            "unknown",
@@ -36,11 +47,14 @@ func CheckAuth(repoPath string) (*AuthResult, error) {
 	}
 
 	// Track auth method indicators
-	oauthCount := 0
+	oauthSignals := make(map[int]bool) // Distinct OAuth patterns seen in trusted locations
+	hasRefresh := false
+	hasScopes := false
 	staticSecretCount := 0
+	countedSecrets := 0
 
 	// Scan all files
-	err := filepath.Walk(repoPath, func(path string, info os.FileInfo, err error) error {
+	err := filepath.Walk(rc.Path, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
@@ -59,19 +73,24 @@ func CheckAuth(repoPath string) (*AuthResult, error) {
 		}
 
 		contentStr := string(content)
-		relativePath := strings.TrimPrefix(path, repoPath+"/")
-
-		// Check for OAuth indicators
-		for _, pattern := range oauthPatterns {
-			if pattern.MatchString(contentStr) {
-				oauthCount++
-				break
+		relativePath := rc.RelPath(path)
+		fc := rc.Context(relativePath)
+		counts := rc.Counts(fc)
+
+		// Check for OAuth indicators (only trusted locations decide the method)
+		if counts {
+			for i, pattern := range oauthPatterns {
+				if pattern.MatchString(contentStr) {
+					oauthSignals[i] = true
+				}
 			}
+			hasRefresh = hasRefresh || oauthRefreshPattern.MatchString(contentStr)
+			hasScopes = hasScopes || oauthScopePattern.MatchString(contentStr)
 		}
 
 		// Check for static secret indicators
 		for _, pattern := range staticSecretPatterns {
-			if pattern.MatchString(contentStr) {
+			if counts && pattern.MatchString(contentStr) {
 				staticSecretCount++
 				// Extract env var names
 				matches := pattern.FindAllString(contentStr, -1)
@@ -85,6 +104,12 @@ func CheckAuth(repoPath string) (*AuthResult, error) {
 
 		// Check for committed secrets
 		secrets := findCommittedSecrets(contentStr, relativePath)
+		for i := range secrets {
+			secrets[i].FileContext = fc
+		}
+		if rc.Confident(fc) {
+			countedSecrets += len(secrets)
+		}
 		result.CommittedSecrets = append(result.CommittedSecrets, secrets...)
 
 		// Check .gitignore for .env
@@ -92,10 +117,14 @@ func CheckAuth(repoPath string) (*AuthResult, error) {
 			if !strings.Contains(contentStr, ".env") {
 				// .env not in .gitignore - CRITICAL
 				result.CommittedSecrets = append(result.CommittedSecrets, SecretFinding{
-					FilePath:   relativePath,
-					SecretType: "missing_gitignore_entry",
-					Snippet:    ".env file not excluded in .gitignore",
+					FilePath:    relativePath,
+					SecretType:  "missing_gitignore_entry",
+					Snippet:     ".env file not excluded in .gitignore",
+					FileContext: fc,
 				})
+				if rc.Confident(fc) {
+					countedSecrets++
+				}
 			}
 		}
 
@@ -109,14 +138,11 @@ func CheckAuth(repoPath string) (*AuthResult, error) {
 	// Deduplicate env vars
 	result.EnvVarsReferenced = uniqueStrings(result.EnvVarsReferenced)
 
-	// Determine auth method
-	if oauthCount >= 2 {
+	// Determine auth method: we need at least two distinct implementation signals
+	if len(oauthSignals) >= 2 {
 		result.Method = "oauth2"
-		// Check for token refresh
-		tokenRefresh := oauthCount >= 3 // If we found refresh_token pattern
-		result.TokenRefresh = &tokenRefresh
-		scopedPerms := true // Assume OAuth has scoping
-		result.ScopedPermissions = &scopedPerms
+		result.TokenRefresh = &hasRefresh
+		result.ScopedPermissions = &hasScopes
 	} else if staticSecretCount > 0 {
 		result.Method = "static_key"
 	} else {
@@ -124,12 +150,12 @@ func CheckAuth(repoPath string) (*AuthResult, error) {
 	}
 
 	// Determine status
-	if len(result.CommittedSecrets) > 0 || result.Method == "none" {
+	if countedSecrets > 0 || result.Method == "none" {
 		result.Status = "critical"
 	} else if result.Method == "static_key" {
 		result.Status = "warning"
 	} else if result.Method == "oauth2" {
-		if result.TokenRefresh != nil && !*result.TokenRefresh {
+		if !*result.TokenRefresh || !*result.ScopedPermissions {
 			result.Status = "warning"
 		} else {
 			result.Status = "pass"
@@ -139,74 +165,23 @@ func CheckAuth(repoPath string) (*AuthResult, error) {
 	return result, nil
 }
 
-// findCommittedSecrets scans content for committed secrets
-func findCommittedSecrets(content, filePath string) []SecretFinding {
-	secrets := make([]SecretFinding, 0)
-
-	// Split into lines for line number tracking
-	lines := strings.Split(content, "\n")
-
-	for lineNum, line := range lines {
-		// AWS keys
-		if awsKeyPattern.MatchString(line) {
-			secrets = append(secrets, SecretFinding{
-				FilePath:   filePath,
-				SecretType: "aws_key",
-				LineNumber: lineNum + 1,
-				Snippet:    redactSecret(line),
-			})
-		}
-
-		// GitHub PAT
-		if githubPatPattern.MatchString(line) || githubPat2Pattern.MatchString(line) {
-			secrets = append(secrets, SecretFinding{
-				FilePath:   filePath,
-				SecretType: "github_pat",
-				LineNumber: lineNum + 1,
-				Snippet:    redactSecret(line),
-			})
-		}
-
-		// Private keys
-		if privateKeyPattern.MatchString(line) {
-			secrets = append(secrets, SecretFinding{
-				FilePath:   filePath,
-				SecretType: "private_key",
-				LineNumber: lineNum + 1,
-				Snippet:    "Private key detected",
-			})
-		}
+// AddHistory merges history findings into the result
+// Secrets removed from HEAD are still public: checksum-validated ones are critical, others a warning
+func (r *AuthResult) AddHistory(rc *RepoContext, findings []HistorySecretFinding, info *HistoryScanInfo) {
+	r.HistorySecrets = findings
+	r.HistoryScan = info
 
-		// Slack tokens
-		if slackTokenPattern.MatchString(line) {
-			secrets = append(secrets, SecretFinding{
-				FilePath:   filePath,
-				SecretType: "slack_token",
-				LineNumber: lineNum + 1,
-				Snippet:    redactSecret(line),
-			})
+	for _, finding := range findings {
+		// Secrets still at HEAD are already counted in CommittedSecrets
+		if finding.StillAtHead || !rc.Confident(finding.FileContext) {
+			continue
 		}
-
-		// Generic API keys
-		if genericKeyPattern.MatchString(line) {
-			secrets = append(secrets, SecretFinding{
-				FilePath:   filePath,
-				SecretType: "generic_key",
-				LineNumber: lineNum + 1,
-				Snippet:    redactSecret(line),
-			})
+		if finding.Validated {
+			r.Status = escalateStatus(r.Status, "critical")
+		} else {
+			r.Status = escalateStatus(r.Status, "warning")
 		}
 	}
-
-	return secrets
-}
-
-// redactSecret redacts sensitive parts of a line
-func redactSecret(line string) string {
-	if len(line) > 50 {
-		return line[:20] + "***REDACTED***" + line[len(line)-10:]
-	}
-	return "***REDACTED***"
 }
 
 // uniqueStrings removes duplicates from a string slice
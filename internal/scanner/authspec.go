@@ -0,0 +1,201 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// Conformance requirement statuses
+const (
+	ConformancePass          = "pass"
+	ConformanceFail          = "fail"
+	ConformanceUnknown       = "unknown"
+	ConformanceNotApplicable = "not_applicable"
+)
+
+// AuthConformance is the result of checking an HTTP server against the MCP authorization spec
+type AuthConformance struct {
+	Requirements []ConformanceRequirement `json:"requirements"`
+}
+
+// ConformanceRequirement reports one requirement of the MCP authorization spec
+type ConformanceRequirement struct {
+	ID       string     `json:"id"`
+	Title    string     `json:"title"`
+	Status   string     `json:"status"` // "pass", "fail", "unknown", "not_applicable"
+	Severity string     `json:"severity"`
+	Evidence []Evidence `json:"evidence,omitempty"`
+}
+
+// Patterns for MCP authorization spec conformance
+var (
+	// RFC 9728 protected resource metadata
+	protectedResourceMetadataPattern = regexp.MustCompile(`(?i)oauth-protected-resource|mcpAuthMetadataRouter|ProtectedResourceMetadata|protected_resource_metadata|authorization_servers`)
+
+	// 401 responses advertising the metadata document
+	wwwAuthenticatePattern      = regexp.MustCompile(`(?i)WWW-Authenticate`)
+	resourceMetadataHintPattern = regexp.MustCompile(`(?i)resource_metadata\s*=|resourceMetadataUrl|resource_metadata_url`)
+
+	// Token audience / resource indicator validation
+	audienceValidationPattern = regexp.MustCompile(`(?i)\baudience\s*[:=]|["']aud["']|\.aud\b|verify_aud|validate_?audience|checkResourceAllowed|resourceServerUrl|expected_?audience|resource_?indicator`)
+
+	// Bearer token verification of any kind
+	bearerVerificationPattern = regexp.MustCompile(`(?i)requireBearerAuth|verifyAccessToken|verify_token|jwtVerify|jwt\.verify|jwt\.decode|introspect|TokenVerifier|BearerAuthBackend`)
+
+	// Incoming Authorization header forwarded to an upstream request
+	tokenPassthroughPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)Authorization["']?\s*:\s*req(?:uest)?\.headers(?:\.authorization|\[["']authorization["']\])`),
+		regexp.MustCompile(`(?i)Authorization["']?\s*:\s*(?:req|request|ctx|c)\.(?:get|header)\(\s*["']authorization["']\s*\)`),
+		regexp.MustCompile(`(?i)["']Authorization["']\s*:\s*request\.headers(?:\.get\(\s*|\[)["']Authorization["']`),
+		regexp.MustCompile(`(?i)headers\s*[:=]\s*(?:req|request)\.headers\b`),
+		regexp.MustCompile(`(?i)authInfo\.token\b[^\n]{0,80}(?:fetch|axios|got|request)|(?:fetch|axios|got)\([^\n]{0,120}authInfo\.token`),
+	}
+
+	// Authorization server endpoints implemented by the server itself
+	authorizationServerPattern = regexp.MustCompile(`(?i)mcpAuthRouter|/authorize\b|authorization_endpoint|OAuthServerProvider|oauth-authorization-server|AuthorizationServer`)
+
+	// PKCE support
+	pkcePattern = regexp.MustCompile(`(?i)code_challenge|code_verifier|codeChallenge|codeVerifier|\bS256\b|\bpkce\b`)
+)
+
+// CheckAuthConformance evaluates an HTTP-transport server against the MCP authorization spec
+func CheckAuthConformance(rc *RepoContext) (*AuthConformance, error) {
+	var prm, wwwAuth, metadataHint, audience, bearer, passthrough, authServer, pkce []Evidence
+
+	err := filepath.Walk(rc.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if skipDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !scanExtensions[filepath.Ext(path)] {
+			return nil
+		}
+
+		relPath := rc.RelPath(path)
+		fc := rc.Context(relPath)
+		if !rc.Counts(fc) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		contentStr := string(content)
+
+		prm = append(prm, findEvidence(contentStr, relPath, fc, protectedResourceMetadataPattern, 3)...)
+		wwwAuth = append(wwwAuth, findEvidence(contentStr, relPath, fc, wwwAuthenticatePattern, 3)...)
+		metadataHint = append(metadataHint, findEvidence(contentStr, relPath, fc, resourceMetadataHintPattern, 3)...)
+		audience = append(audience, findEvidence(contentStr, relPath, fc, audienceValidationPattern, 3)...)
+		bearer = append(bearer, findEvidence(contentStr, relPath, fc, bearerVerificationPattern, 3)...)
+		authServer = append(authServer, findEvidence(contentStr, relPath, fc, authorizationServerPattern, 3)...)
+		pkce = append(pkce, findEvidence(contentStr, relPath, fc, pkcePattern, 3)...)
+		for _, pattern := range tokenPassthroughPatterns {
+			passthrough = append(passthrough, findEvidence(contentStr, relPath, fc, pattern, 3)...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk repository: %w", err)
+	}
+
+	result := &AuthConformance{Requirements: make([]ConformanceRequirement, 0, 5)}
+
+	// 1. Protected resource metadata (RFC 9728)
+	req := ConformanceRequirement{
+		ID:       "protected_resource_metadata",
+		Title:    "Serves OAuth protected resource metadata (/.well-known/oauth-protected-resource)",
+		Status:   ConformanceFail,
+		Severity: "warning",
+	}
+	if len(prm) > 0 {
+		req.Status = ConformancePass
+		req.Evidence = prm
+	}
+	result.Requirements = append(result.Requirements, req)
+
+	// 2. WWW-Authenticate on 401 pointing at the metadata
+	req = ConformanceRequirement{
+		ID:       "www_authenticate",
+		Title:    "Returns WWW-Authenticate with resource_metadata on 401 responses",
+		Status:   ConformanceFail,
+		Severity: "warning",
+	}
+	switch {
+	case len(wwwAuth) > 0 && len(metadataHint) > 0:
+		req.Status = ConformancePass
+		req.Evidence = append(wwwAuth, metadataHint...)
+	case len(wwwAuth) > 0:
+		// Header present but without the metadata pointer clients need for discovery
+		req.Evidence = wwwAuth
+	}
+	result.Requirements = append(result.Requirements, req)
+
+	// 3. Audience / resource validation of access tokens
+	req = ConformanceRequirement{
+		ID:       "audience_validation",
+		Title:    "Validates that access tokens were issued for this server (audience / resource)",
+		Status:   ConformanceFail,
+		Severity: "warning",
+	}
+	switch {
+	case len(audience) > 0:
+		req.Status = ConformancePass
+		req.Evidence = audience
+	case len(bearer) > 0:
+		// Tokens are verified somewhere, but we can't see an audience check
+		req.Status = ConformanceUnknown
+		req.Evidence = bearer
+	}
+	result.Requirements = append(result.Requirements, req)
+
+	// 4. No token passthrough to upstream APIs
+	req = ConformanceRequirement{
+		ID:       "no_token_passthrough",
+		Title:    "Does not forward client access tokens to upstream APIs",
+		Status:   ConformancePass,
+		Severity: "critical",
+	}
+	if len(passthrough) > 0 {
+		req.Status = ConformanceFail
+		req.Evidence = passthrough
+	}
+	result.Requirements = append(result.Requirements, req)
+
+	// 5. PKCE, only relevant when the server runs its own authorization flow
+	req = ConformanceRequirement{
+		ID:       "pkce",
+		Title:    "Uses PKCE (S256) for authorization code flows",
+		Status:   ConformanceNotApplicable,
+		Severity: "warning",
+	}
+	if len(authServer) > 0 {
+		req.Status = ConformanceFail
+		req.Evidence = authServer
+		if len(pkce) > 0 {
+			req.Status = ConformancePass
+			req.Evidence = pkce
+		}
+	}
+	result.Requirements = append(result.Requirements, req)
+
+	return result, nil
+}
+
+// AddConformance attaches conformance results and raises the auth status for failed requirements
+func (r *AuthResult) AddConformance(c *AuthConformance) {
+	r.Conformance = c
+	for _, req := range c.Requirements {
+		if req.Status == ConformanceFail {
+			r.Status = escalateStatus(r.Status, req.Severity)
+		}
+	}
+}
@@ -0,0 +1,489 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FileClass describes the role a file plays in a repository
+type FileClass string
+
+const (
+	ClassProduction FileClass = "production"
+	ClassTest       FileClass = "test"
+	ClassExample    FileClass = "example"
+	ClassDocs       FileClass = "docs"
+	ClassGenerated  FileClass = "generated"
+	ClassVendored   FileClass = "vendored"
+)
+
+// ContextWeights maps a file class to the confidence assigned to findings in it
+type ContextWeights map[FileClass]float64
+
+// DefaultContextWeights are used for classes without a configured weight
+var DefaultContextWeights = ContextWeights{
+	ClassProduction: 1.0,
+	ClassTest:       0.3,
+	ClassExample:    0.4,
+	ClassDocs:       0.3,
+	ClassGenerated:  0.5,
+	ClassVendored:   0.2,
+}
+
+// DefaultMinConfidence is the confidence a finding needs to affect check status
+const DefaultMinConfidence = 0.5
+
+// FileContext describes where a finding was made and how much it should be trusted
+type FileContext struct {
+	FileClass  FileClass `json:"file_class,omitempty"`
+	Entrypoint bool      `json:"entrypoint,omitempty"`
+	Reachable  *bool     `json:"reachable,omitempty"` // nil when no entrypoint is known or the file isn't source code
+	Confidence float64   `json:"confidence"`
+}
+
+// ContextSummary is the repository classification stored with each scan
+type ContextSummary struct {
+	Entrypoints  []string             `json:"entrypoints"`
+	FileClasses  map[FileClass]int    `json:"file_classes"`
+	Reachability *ReachabilitySummary `json:"reachability,omitempty"`
+}
+
+// RepoContext carries per-repository information shared by all checks
+type RepoContext struct {
+	Path             string
+	Entrypoints      []string // Relative paths of detected package entrypoints
+	weights          ContextWeights
+	minConfidence    float64
+	scoreUnreachable bool
+	entrySet         map[string]bool
+	reachable        map[string]bool // nil when no entrypoint was detected
+	classes          map[string]FileClass
+	classCounts      map[FileClass]int
+	reachCounts      ReachabilitySummary
+}
+
+// NewRepoContext classifies a cloned repository and detects its entrypoints
+func NewRepoContext(repoPath string, opts Options) *RepoContext {
+	weights := make(ContextWeights, len(DefaultContextWeights))
+	for class, weight := range DefaultContextWeights {
+		weights[class] = weight
+	}
+	for class, weight := range opts.ContextWeights {
+		weights[class] = weight
+	}
+
+	minConfidence := DefaultMinConfidence
+	if opts.MinConfidence != nil {
+		minConfidence = *opts.MinConfidence
+	}
+
+	rc := &RepoContext{
+		Path:             repoPath,
+		weights:          weights,
+		minConfidence:    minConfidence,
+		scoreUnreachable: opts.ScoreUnreachable,
+		entrySet:         make(map[string]bool),
+		classes:          make(map[string]FileClass),
+		classCounts:      make(map[FileClass]int),
+	}
+
+	rc.Entrypoints = detectEntrypoints(repoPath)
+	for _, entry := range rc.Entrypoints {
+		rc.entrySet[entry] = true
+	}
+	if len(rc.Entrypoints) > 0 {
+		rc.reachable = buildReachability(repoPath, rc.Entrypoints)
+	}
+
+	filepath.Walk(repoPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if skipDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel := rc.RelPath(p)
+		class := ClassifyFile(rel)
+		if class == ClassProduction && isGeneratedContent(p) {
+			class = ClassGenerated
+		}
+		rc.classes[rel] = class
+		rc.classCounts[class]++
+		if rc.reachable != nil && isReachabilitySource(rel) {
+			if rc.reachable[rel] {
+				rc.reachCounts.Reachable++
+			} else {
+				rc.reachCounts.Unreachable++
+			}
+		}
+		return nil
+	})
+
+	return rc
+}
+
+// RelPath converts an absolute path inside the repository to a slash-separated relative path
+func (rc *RepoContext) RelPath(p string) string {
+	rel, err := filepath.Rel(rc.Path, p)
+	if err != nil {
+		return filepath.ToSlash(p)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Context returns the file context for a relative path
+func (rc *RepoContext) Context(relPath string) FileContext {
+	class, ok := rc.classes[relPath]
+	if !ok {
+		class = ClassifyFile(relPath)
+	}
+
+	fc := FileContext{
+		FileClass:  class,
+		Entrypoint: rc.entrySet[relPath],
+		Confidence: rc.weights[class],
+	}
+	if rc.reachable != nil && isReachabilitySource(relPath) {
+		reachable := rc.reachable[relPath]
+		fc.Reachable = &reachable
+	}
+	return fc
+}
+
+// Counts reports whether a finding with this context should affect check status
+// Unreachable code is reported but only counts when ScoreUnreachable is set
+func (rc *RepoContext) Counts(fc FileContext) bool {
+	if fc.Reachable != nil && !*fc.Reachable && !rc.scoreUnreachable {
+		return false
+	}
+	return rc.Confident(fc)
+}
+
+// Confident reports whether a finding's location is trusted, regardless of reachability
+// Committed secrets use this because they leak whether or not the code ever runs
+func (rc *RepoContext) Confident(fc FileContext) bool {
+	return fc.Confidence >= rc.minConfidence
+}
+
+// Summary returns the classification summary stored with the scan
+func (rc *RepoContext) Summary() *ContextSummary {
+	summary := &ContextSummary{
+		Entrypoints: rc.Entrypoints,
+		FileClasses: rc.classCounts,
+	}
+	if rc.reachable != nil {
+		reachCounts := rc.reachCounts
+		summary.Reachability = &reachCounts
+	}
+	return summary
+}
+
+// ToJSON converts ContextSummary to JSON
+func (s *ContextSummary) ToJSON() (json.RawMessage, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(data), nil
+}
+
+// Directory names that mark the class of everything below them
+var classDirs = map[string]FileClass{
+	"test":          ClassTest,
+	"tests":         ClassTest,
+	"__tests__":     ClassTest,
+	"spec":          ClassTest,
+	"testdata":      ClassTest,
+	"fixtures":      ClassTest,
+	"__fixtures__":  ClassTest,
+	"__mocks__":     ClassTest,
+	"e2e":           ClassTest,
+	"example":       ClassExample,
+	"examples":      ClassExample,
+	"demo":          ClassExample,
+	"demos":         ClassExample,
+	"sample":        ClassExample,
+	"samples":       ClassExample,
+	"playground":    ClassExample,
+	"docs":          ClassDocs,
+	"doc":           ClassDocs,
+	"documentation": ClassDocs,
+	"generated":     ClassGenerated,
+	"__generated__": ClassGenerated,
+	"gen":           ClassGenerated,
+	"vendor":        ClassVendored,
+	"third_party":   ClassVendored,
+	"third-party":   ClassVendored,
+}
+
+// ClassifyFile determines the class of a file from its relative path
+func ClassifyFile(relPath string) FileClass {
+	relPath = filepath.ToSlash(relPath)
+	base := path.Base(relPath)
+	lower := strings.ToLower(base)
+
+	// Directory-based classification, outermost directory wins
+	dirs := strings.Split(path.Dir(relPath), "/")
+	for _, dir := range dirs {
+		if class, ok := classDirs[strings.ToLower(dir)]; ok {
+			return class
+		}
+	}
+
+	// Test files
+	switch {
+	case strings.Contains(lower, ".test.") || strings.Contains(lower, ".spec."):
+		return ClassTest
+	case strings.HasPrefix(lower, "test_") && strings.HasSuffix(lower, ".py"):
+		return ClassTest
+	case strings.HasSuffix(lower, "_test.py") || strings.HasSuffix(lower, "_test.go"):
+		return ClassTest
+	case lower == "conftest.py":
+		return ClassTest
+	}
+
+	// Example files
+	if strings.Contains(lower, ".example") || strings.HasPrefix(lower, "example.") || strings.HasPrefix(lower, "example_") {
+		return ClassExample
+	}
+
+	// Documentation
+	switch path.Ext(lower) {
+	case ".md", ".mdx", ".rst", ".txt", ".adoc":
+		return ClassDocs
+	}
+
+	// Generated files
+	switch {
+	case strings.HasSuffix(lower, ".min.js"), strings.HasSuffix(lower, ".d.ts"),
+		strings.HasSuffix(lower, ".map"), strings.HasSuffix(lower, "_pb2.py"),
+		strings.HasSuffix(lower, ".pb.go"), strings.HasSuffix(lower, ".generated.ts"):
+		return ClassGenerated
+	case lower == "package-lock.json", lower == "yarn.lock", lower == "pnpm-lock.yaml",
+		lower == "poetry.lock", lower == "uv.lock":
+		return ClassGenerated
+	}
+
+	return ClassProduction
+}
+
+// isGeneratedContent checks a file header for a code generation marker
+func isGeneratedContent(p string) bool {
+	f, err := os.Open(p)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	header := make([]byte, 512)
+	n, _ := f.Read(header)
+	header = bytes.ToLower(header[:n])
+	return bytes.Contains(header, []byte("do not edit")) &&
+		(bytes.Contains(header, []byte("generated")) || bytes.Contains(header, []byte("autogenerated")))
+}
+
+// detectEntrypoints finds the published entrypoints declared in package manifests
+func detectEntrypoints(repoPath string) []string {
+	entries := make([]string, 0)
+
+	filepath.Walk(repoPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if skipDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, _ := filepath.Rel(repoPath, p)
+		rel = filepath.ToSlash(rel)
+		if ClassifyFile(rel) != ClassProduction {
+			return nil
+		}
+
+		manifestDir := path.Dir(rel)
+		switch info.Name() {
+		case "package.json":
+			for _, target := range packageJSONEntrypoints(p) {
+				if resolved := resolveJSEntrypoint(repoPath, path.Join(manifestDir, target)); resolved != "" {
+					entries = append(entries, resolved)
+				}
+			}
+		case "pyproject.toml":
+			for _, target := range pyprojectEntrypoints(p) {
+				if resolved := resolvePythonModule(repoPath, manifestDir, target); resolved != "" {
+					entries = append(entries, resolved)
+				}
+			}
+		}
+		return nil
+	})
+
+	entries = uniqueStrings(entries)
+	sort.Strings(entries)
+	return entries
+}
+
+// packageJSONEntrypoints reads main, bin and exports targets from a package.json
+func packageJSONEntrypoints(p string) []string {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil
+	}
+
+	var pkg struct {
+		Main    string          `json:"main"`
+		Module  string          `json:"module"`
+		Bin     json.RawMessage `json:"bin"`
+		Exports json.RawMessage `json:"exports"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil
+	}
+
+	targets := make([]string, 0)
+	if pkg.Main != "" {
+		targets = append(targets, pkg.Main)
+	}
+	if pkg.Module != "" {
+		targets = append(targets, pkg.Module)
+	}
+	targets = append(targets, collectJSONStrings(pkg.Bin)...)
+	targets = append(targets, collectJSONStrings(pkg.Exports)...)
+	return targets
+}
+
+// collectJSONStrings returns every string value in a JSON string, array or nested object
+func collectJSONStrings(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil
+	}
+
+	out := make([]string, 0)
+	var collect func(v interface{})
+	collect = func(v interface{}) {
+		switch t := v.(type) {
+		case string:
+			out = append(out, t)
+		case []interface{}:
+			for _, item := range t {
+				collect(item)
+			}
+		case map[string]interface{}:
+			for _, item := range t {
+				collect(item)
+			}
+		}
+	}
+	collect(value)
+	return out
+}
+
+// resolveJSEntrypoint maps a manifest target to a source file, following build output back to src/
+func resolveJSEntrypoint(repoPath, target string) string {
+	target = path.Clean(strings.TrimPrefix(target, "./"))
+	if strings.HasPrefix(target, "..") || strings.Contains(target, "*") {
+		return ""
+	}
+
+	candidates := []string{target}
+	for _, outDir := range []string{"dist/", "build/", "lib/", "out/"} {
+		if idx := strings.Index(target, outDir); idx >= 0 && (idx == 0 || target[idx-1] == '/') {
+			candidates = append(candidates, target[:idx]+"src/"+target[idx+len(outDir):])
+		}
+	}
+
+	for _, candidate := range candidates {
+		if resolved := resolveJSFile(repoPath, candidate); resolved != "" {
+			return resolved
+		}
+	}
+	return ""
+}
+
+// resolveJSFile finds an existing file for an import path, trying source extensions and index files
+func resolveJSFile(repoPath, target string) string {
+	stem := strings.TrimSuffix(target, path.Ext(target))
+	candidates := []string{target}
+	for _, ext := range []string{".ts", ".tsx", ".mts", ".js", ".mjs", ".cjs", ".jsx"} {
+		candidates = append(candidates, stem+ext, target+ext)
+	}
+	for _, ext := range []string{".ts", ".js", ".mjs"} {
+		candidates = append(candidates, target+"/index"+ext)
+	}
+
+	for _, candidate := range candidates {
+		info, err := os.Stat(filepath.Join(repoPath, filepath.FromSlash(candidate)))
+		if err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// pyprojectEntrypoints reads script targets ("pkg.module:func") from a pyproject.toml
+func pyprojectEntrypoints(p string) []string {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	targets := make([]string, 0)
+	inScripts := false
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "[") {
+			section := strings.Trim(line, "[] ")
+			inScripts = section == "project.scripts" || section == "tool.poetry.scripts" ||
+				section == "project.gui-scripts"
+			continue
+		}
+		if !inScripts || line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value := strings.Trim(strings.TrimSpace(parts[1]), `"'`)
+		module := strings.SplitN(value, ":", 2)[0]
+		if module != "" {
+			targets = append(targets, module)
+		}
+	}
+	return targets
+}
+
+// resolvePythonModule maps a dotted module name to a file relative to the repository
+func resolvePythonModule(repoPath, baseDir, module string) string {
+	modPath := strings.ReplaceAll(module, ".", "/")
+	for _, root := range []string{baseDir, path.Join(baseDir, "src")} {
+		for _, candidate := range []string{modPath + ".py", modPath + "/__init__.py", modPath + "/__main__.py"} {
+			rel := path.Clean(path.Join(root, candidate))
+			info, err := os.Stat(filepath.Join(repoPath, filepath.FromSlash(rel)))
+			if err == nil && !info.IsDir() {
+				return rel
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,446 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// InstallCommand is the command a README tells users to run, with the risks found in it
+type InstallCommand struct {
+	Command  string   `json:"command"`
+	Warnings []string `json:"warnings,omitempty"`
+	Evidence
+}
+
+// clientServerEntry is one server in an MCP client config (Claude Desktop, Cursor, VS Code, Zed)
+type clientServerEntry struct {
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env"`
+	URL     string            `json:"url"`
+}
+
+// Client config rule names, severities and messages
+var clientConfigRules = map[string]struct {
+	Severity string
+	Message  string
+}{
+	"client_config_plaintext_secret": {"critical", "Client config snippet embeds a real-looking secret in env or args"},
+	"client_config_root_mount":       {"critical", "Client config grants the server the whole filesystem"},
+	"client_config_home_mount":       {"warning", "Client config grants the server the entire home directory"},
+	"client_config_allow_all":        {"warning", "Client config disables the server's permission checks"},
+	"client_config_no_sandbox":       {"warning", "Client config disables sandboxing"},
+	"client_config_insecure_url":     {"warning", "Client config connects to a remote server over plain HTTP"},
+}
+
+// Patterns for client config snippets
+var (
+	fencePattern = regexp.MustCompile("(?m)^\\s*(```|~~~)\\s*([A-Za-z0-9_+-]*)[^\\n]*\\n")
+
+	jsonLineCommentPattern   = regexp.MustCompile(`(?m)^\s*//.*$`)
+	jsonTrailingCommaPattern = regexp.MustCompile(`,(\s*[}\]])`)
+
+	allowAllArgPattern  = regexp.MustCompile(`(?i)^--(?:allow-all(?:-\w+)?|yolo|dangerously-[\w-]+|unsafe(?:-[\w-]+)?|disable-auth|no-confirm(?:ation)?|auto-approve)$`)
+	noSandboxArgPattern = regexp.MustCompile(`(?i)^--(?:no-sandbox|disable-sandbox|no-isolation|disable-isolation)$`)
+	homeDirPattern      = regexp.MustCompile(`(?i)^(?:~|\$HOME|\$\{HOME\}|%USERPROFILE%|/Users/[^/]+|/home/[^/]+|/root|[A-Z]:\\\\?Users\\\\?[^\\/]+)[/\\]?$`)
+
+	// Shell install commands, used when no client config snippet is present
+	installShellPattern = regexp.MustCompile(`(?m)^\s*\$?\s*((?:npx|uvx|pipx run|docker run|npm (?:install|i) -g|pip install|uv tool install|go install|bunx)\s+[^\n]+)$`)
+
+	secretEnvNamePattern = regexp.MustCompile(`(?i)token|secret|key|password|passwd|credential|auth`)
+)
+
+// configBlock is a fenced code block from a markdown file
+type configBlock struct {
+	lang   string
+	body   string
+	offset int // Byte offset of the body in the file
+}
+
+// CheckClientConfigs audits MCP client config snippets in markdown files
+// It returns findings for risky arguments and env values, and the command users are told to run
+func CheckClientConfigs(rc *RepoContext) ([]ExposureFinding, *InstallCommand) {
+	findings := make(map[string]*ExposureFinding)
+	var install *InstallCommand
+	var shellInstall *InstallCommand
+
+	// README first so its install command wins
+	files := markdownFiles(rc)
+	for _, path := range files {
+		relPath := rc.RelPath(path)
+		fc := rc.Context(relPath)
+		if fc.FileClass == ClassTest || fc.FileClass == ClassVendored || fc.FileClass == ClassGenerated {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		contentStr := string(content)
+
+		for _, block := range fencedBlocks(contentStr) {
+			entries := parseClientConfig(block)
+			if entries == nil {
+				if shellInstall == nil {
+					if m := installShellPattern.FindStringSubmatchIndex(block.body); m != nil {
+						command := strings.TrimSpace(block.body[m[2]:m[3]])
+						fields := strings.Fields(command)
+						for i, field := range fields {
+							fields[i] = strings.Trim(field, `"'`)
+						}
+						secrets := secretArgs(fields)
+						shellInstall = &InstallCommand{
+							Command:  redactSecrets(command, secrets),
+							Evidence: redactedEvidenceAt(contentStr, relPath, fc, block.offset+m[2], secrets),
+						}
+					}
+				}
+				continue
+			}
+
+			names := make([]string, 0, len(entries))
+			for name := range entries {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				entry := entries[name]
+				secrets := clientSecrets(entry)
+				// Snippets are whole config lines, so any secret on them is redacted
+				locate := func(needle string) Evidence {
+					offset := block.offset
+					if i := strings.Index(block.body, needle); i >= 0 {
+						offset += i
+					}
+					return redactedEvidenceAt(contentStr, relPath, fc, offset, secrets)
+				}
+
+				hits := auditClientEntry(entry, secrets, locate)
+				for _, hit := range hits {
+					f, ok := findings[hit.Rule]
+					if !ok {
+						f = &ExposureFinding{Rule: hit.Rule, Severity: hit.Severity, Message: hit.Message, Evidence: make([]Evidence, 0)}
+						findings[hit.Rule] = f
+					}
+					if len(f.Evidence) < 5 {
+						f.Evidence = append(f.Evidence, hit.Evidence...)
+					}
+				}
+
+				if install == nil && entry.Command != "" {
+					install = &InstallCommand{
+						Command:  redactSecrets(shellJoin(append([]string{entry.Command}, entry.Args...)), secrets),
+						Evidence: locate(strconv.Quote(entry.Command)),
+					}
+					for _, hit := range hits {
+						install.Warnings = append(install.Warnings, hit.Message)
+					}
+				}
+			}
+		}
+	}
+
+	if install == nil && shellInstall != nil {
+		install = shellInstall
+		// Run the command through the container rules when it's a docker run
+		if fields := strings.Fields(install.Command); len(fields) > 2 && fields[0] == "docker" && fields[1] == "run" {
+			scan := &containerScan{}
+			scan.dockerRun(fields[2:], install.Evidence)
+			for _, f := range scan.list() {
+				if f.Severity != "info" {
+					install.Warnings = append(install.Warnings, f.Message)
+				}
+			}
+		}
+	}
+
+	result := make([]ExposureFinding, 0, len(findings))
+	for _, rule := range []string{
+		"client_config_plaintext_secret", "client_config_root_mount", "client_config_home_mount",
+		"client_config_allow_all", "client_config_no_sandbox", "client_config_insecure_url",
+	} {
+		if f, ok := findings[rule]; ok {
+			result = append(result, *f)
+		}
+	}
+	// Docker-based entries reuse the container rules
+	for _, rule := range containerRuleOrder {
+		if f, ok := findings[rule]; ok {
+			result = append(result, *f)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return statusRank[result[i].Severity] > statusRank[result[j].Severity]
+	})
+
+	return result, install
+}
+
+// auditClientEntry returns the findings for one configured server
+func auditClientEntry(entry clientServerEntry, secrets []clientSecret, locate func(needle string) Evidence) []ExposureFinding {
+	hits := make([]ExposureFinding, 0)
+	hit := func(rule string, ev Evidence) {
+		def := clientConfigRules[rule]
+		hits = append(hits, ExposureFinding{Rule: rule, Severity: def.Severity, Message: def.Message, Evidence: []Evidence{ev}})
+	}
+
+	for i, arg := range entry.Args {
+		ev := locate(strconv.Quote(arg))
+		switch {
+		case allowAllArgPattern.MatchString(arg):
+			hit("client_config_allow_all", ev)
+		case noSandboxArgPattern.MatchString(arg):
+			hit("client_config_no_sandbox", ev)
+		}
+
+		// Paths handed to the server, including docker bind mount sources
+		path := arg
+		if i > 0 && (entry.Args[i-1] == "-v" || entry.Args[i-1] == "--volume") {
+			path, _, _ = strings.Cut(arg, ":")
+		}
+		switch {
+		case path == "/" || path == `C:\` || path == "C:/":
+			hit("client_config_root_mount", ev)
+		case homeDirPattern.MatchString(path):
+			hit("client_config_home_mount", ev)
+		}
+	}
+
+	// Docker entries go through the container rules
+	if filepath.Base(entry.Command) == "docker" && len(entry.Args) > 0 && entry.Args[0] == "run" {
+		scan := &containerScan{}
+		scan.dockerRun(entry.Args[1:], locate(`"run"`))
+		for _, f := range scan.list() {
+			if f.Severity != "info" {
+				hits = append(hits, f)
+			}
+		}
+	}
+
+	// Literal secrets in env or args; locate has already redacted them from the snippets
+	for _, secret := range secrets {
+		if secret.arg < 0 {
+			hit("client_config_plaintext_secret", locate(strconv.Quote(secret.name)))
+		} else {
+			hit("client_config_plaintext_secret", locate(strconv.Quote(entry.Args[secret.arg])))
+		}
+	}
+
+	if strings.HasPrefix(entry.URL, "http://") && !isLocalURL(entry.URL) {
+		hit("client_config_insecure_url", locate(strconv.Quote(entry.URL)))
+	}
+
+	return hits
+}
+
+// clientSecret is a literal secret in a client config entry
+type clientSecret struct {
+	arg   int    // Index in args, or -1 for env
+	name  string // Env var or flag name
+	value string
+}
+
+// clientSecrets returns the real-looking secrets in an entry's env and args
+func clientSecrets(entry clientServerEntry) []clientSecret {
+	secrets := make([]clientSecret, 0)
+	envNames := make([]string, 0, len(entry.Env))
+	for name := range entry.Env {
+		envNames = append(envNames, name)
+	}
+	sort.Strings(envNames)
+	for _, name := range envNames {
+		if isPlaintextSecret(name, entry.Env[name]) {
+			secrets = append(secrets, clientSecret{arg: -1, name: name, value: entry.Env[name]})
+		}
+	}
+	return append(secrets, secretArgs(entry.Args)...)
+}
+
+// secretArgs returns the secrets passed as arguments: --token VALUE, --token=VALUE, docker's
+// -e NAME=VALUE and bare tokens
+func secretArgs(args []string) []clientSecret {
+	secrets := make([]clientSecret, 0)
+	for i, arg := range args {
+		name, value := "", arg
+		if flag, v, ok := strings.Cut(arg, "="); ok {
+			name, value = flag, v
+			if flag == "-e" || flag == "--env" {
+				name, value, _ = strings.Cut(v, "=")
+			}
+		} else if strings.HasPrefix(arg, "-") {
+			continue
+		} else if i > 0 && strings.HasPrefix(args[i-1], "-") {
+			name = args[i-1]
+		}
+		// Key and certificate flags usually take a file path
+		if strings.HasPrefix(value, "/") || strings.HasPrefix(value, "~") || strings.HasPrefix(value, ".") {
+			continue
+		}
+		if isPlaintextSecret(name, value) {
+			secrets = append(secrets, clientSecret{arg: i, name: name, value: value})
+		}
+	}
+	return secrets
+}
+
+// isPlaintextSecret reports whether a named value is a real-looking secret; placeholders and
+// references are fine
+func isPlaintextSecret(name, value string) bool {
+	if value == "" || isPlaceholderSecret(value) || isReferenceValue(value) {
+		return false
+	}
+	if len(detectSecrets(fmt.Sprintf("%s=%q", name, value))) > 0 {
+		return true
+	}
+	return secretEnvNamePattern.MatchString(name) && len(value) >= 16 && shannonEntropy(value) >= genericEntropyThreshold
+}
+
+// redactSecrets replaces each secret value in text with its redacted form
+func redactSecrets(text string, secrets []clientSecret) string {
+	for _, secret := range secrets {
+		text = strings.ReplaceAll(text, secret.value, redactSecret(secret.value))
+	}
+	return text
+}
+
+// redactedEvidenceAt builds evidence for the line containing byte offset with the secrets redacted
+// before the snippet is truncated
+func redactedEvidenceAt(content, relPath string, fc FileContext, offset int, secrets []clientSecret) Evidence {
+	if len(secrets) == 0 {
+		return evidenceAt(content, relPath, fc, offset)
+	}
+	return evidenceAt(redactSecrets(content, secrets), relPath, fc, len(redactSecrets(content[:offset], secrets)))
+}
+
+// parseClientConfig returns the server entries of a JSON client config block, or nil if it isn't one
+func parseClientConfig(block configBlock) map[string]clientServerEntry {
+	switch block.lang {
+	case "json", "jsonc", "json5", "":
+	default:
+		return nil
+	}
+	text := strings.TrimSpace(block.body)
+	if text == "" {
+		return nil
+	}
+	// Snippets are often JSONC fragments: strip comments and trailing commas, wrap bare members
+	text = jsonLineCommentPattern.ReplaceAllString(text, "")
+	text = jsonTrailingCommaPattern.ReplaceAllString(text, "$1")
+	if !strings.HasPrefix(text, "{") {
+		text = "{" + text + "}"
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(text), &doc); err != nil {
+		return nil
+	}
+
+	// Claude Desktop / Cursor: mcpServers, VS Code: servers or mcp.servers, Zed: context_servers
+	for _, key := range []string{"mcpServers", "servers", "context_servers", "mcp"} {
+		raw, ok := doc[key]
+		if !ok {
+			continue
+		}
+		if key == "mcp" {
+			var nested map[string]json.RawMessage
+			if json.Unmarshal(raw, &nested) != nil {
+				continue
+			}
+			if raw, ok = nested["servers"]; !ok {
+				continue
+			}
+		}
+		var entries map[string]clientServerEntry
+		if err := json.Unmarshal(raw, &entries); err != nil || len(entries) == 0 {
+			continue
+		}
+		for _, e := range entries {
+			if e.Command != "" || e.URL != "" {
+				return entries
+			}
+		}
+	}
+
+	return nil
+}
+
+// fencedBlocks returns the fenced code blocks in a markdown document
+func fencedBlocks(content string) []configBlock {
+	blocks := make([]configBlock, 0)
+	pos := 0
+	for {
+		open := fencePattern.FindStringSubmatchIndex(content[pos:])
+		if open == nil {
+			break
+		}
+		fence := content[pos+open[2] : pos+open[3]]
+		lang := strings.ToLower(content[pos+open[4] : pos+open[5]])
+		bodyStart := pos + open[1]
+
+		end := strings.Index(content[bodyStart:], fence)
+		if end < 0 {
+			break
+		}
+		blocks = append(blocks, configBlock{lang: lang, body: content[bodyStart : bodyStart+end], offset: bodyStart})
+		pos = bodyStart + end + len(fence)
+	}
+	return blocks
+}
+
+// markdownFiles lists markdown files, README files first
+func markdownFiles(rc *RepoContext) []string {
+	files := make([]string, 0)
+	filepath.Walk(rc.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if skipDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".md", ".mdx":
+			files = append(files, path)
+		}
+		return nil
+	})
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return isReadme(files[i], rc.Path) && !isReadme(files[j], rc.Path)
+	})
+	return files
+}
+
+// isReadme reports whether path is the top-level README
+func isReadme(path, root string) bool {
+	return filepath.Dir(path) == filepath.Clean(root) && strings.HasPrefix(strings.ToLower(filepath.Base(path)), "readme")
+}
+
+// isLocalURL reports whether a URL points at the local machine
+func isLocalURL(u string) bool {
+	host := strings.TrimPrefix(strings.TrimPrefix(u, "http://"), "https://")
+	return strings.HasPrefix(host, "localhost") || strings.HasPrefix(host, "127.") || strings.HasPrefix(host, "[::1]")
+}
+
+// shellJoin joins arguments into a copyable command, quoting where needed
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'$`\\|&;<>()*?") {
+			quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		} else {
+			quoted[i] = arg
+		}
+	}
+	return strings.Join(quoted, " ")
+}
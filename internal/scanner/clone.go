@@ -2,10 +2,12 @@ package scanner
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -92,6 +94,123 @@ func (cm *CloneManager) pullRepo(ctx context.Context, repoDir string) error {
 	return nil
 }
 
+// FetchHistory deepens a shallow clone so history can be scanned
+// maxCommits of 0 fetches the complete history; otherwise the clone is set to that depth, so the
+// cached clone doesn't grow with every scan
+func (cm *CloneManager) FetchHistory(ctx context.Context, repoDir string, maxCommits int) error {
+	fetchCtx, cancel := context.WithTimeout(ctx, 120*time.Second)
+	defer cancel()
+
+	args := []string{"fetch", "--quiet", "--unshallow"}
+	if maxCommits > 0 {
+		args = []string{"fetch", "--quiet", fmt.Sprintf("--depth=%d", maxCommits)}
+	}
+
+	cmd := exec.CommandContext(fetchCtx, "git", args...)
+	cmd.Dir = repoDir
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+
+	if err := cmd.Run(); err != nil {
+		// Fetching an already complete repository with --unshallow fails; that's fine
+		if _, statErr := os.Stat(filepath.Join(repoDir, ".git", "shallow")); os.IsNotExist(statErr) {
+			return nil
+		}
+		return fmt.Errorf("git fetch history failed: %w", err)
+	}
+
+	return nil
+}
+
+// refPattern matches the tag, branch and commit names CloneRef accepts; nothing that git
+// could read as an option
+var refPattern = regexp.MustCompile(`^[A-Za-z0-9@][A-Za-z0-9._/@+-]*$`)
+
+// CloneRef fetches a single tag, branch or commit into its own directory, trying each ref in
+// turn until one exists; the caller removes the directory when done
+func (cm *CloneManager) CloneRef(ctx context.Context, repoURL string, refs ...string) (string, string, error) {
+	org, repo, err := parseRepoURL(repoURL)
+	if err != nil {
+		return "", "", fmt.Errorf("parse repo URL: %w", err)
+	}
+
+	for _, ref := range refs {
+		if !refPattern.MatchString(ref) || strings.Contains(ref, "..") {
+			return "", "", fmt.Errorf("invalid ref: %q", ref)
+		}
+	}
+
+	hash := sha256.Sum256([]byte(strings.Join(refs, "\x00")))
+	targetDir := filepath.Join(cm.baseDir, org, fmt.Sprintf("%s@%x", repo, hash[:6]))
+	os.RemoveAll(targetDir)
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return "", "", fmt.Errorf("create directory: %w", err)
+	}
+
+	if _, err := cm.git(ctx, targetDir, 10*time.Second, "init", "--quiet"); err != nil {
+		os.RemoveAll(targetDir)
+		return "", "", err
+	}
+	if _, err := cm.git(ctx, targetDir, 10*time.Second, "remote", "add", "origin", repoURL); err != nil {
+		os.RemoveAll(targetDir)
+		return "", "", err
+	}
+
+	for _, ref := range refs {
+		if _, err := cm.git(ctx, targetDir, 60*time.Second, "fetch", "--quiet", "--depth", "1", "origin", ref); err != nil {
+			continue
+		}
+		if _, err := cm.git(ctx, targetDir, 30*time.Second, "checkout", "--quiet", "--detach", "FETCH_HEAD"); err != nil {
+			os.RemoveAll(targetDir)
+			return "", "", err
+		}
+		return targetDir, ref, nil
+	}
+
+	os.RemoveAll(targetDir)
+	return "", "", fmt.Errorf("ref not found: %s", strings.Join(refs, ", "))
+}
+
+// Revision reads the checked-out commit and the repository's default branch
+func (cm *CloneManager) Revision(ctx context.Context, repoDir string) (commitSHA, branch string, err error) {
+	commitSHA, err = cm.git(ctx, repoDir, 10*time.Second, "rev-parse", "HEAD")
+	if err != nil {
+		return "", "", err
+	}
+
+	// Clones of the default branch have it checked out; ref checkouts are detached
+	branch, err = cm.git(ctx, repoDir, 10*time.Second, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil || branch == "HEAD" {
+		branch = ""
+		out, err := cm.git(ctx, repoDir, 30*time.Second, "ls-remote", "--symref", "origin", "HEAD")
+		if err == nil {
+			// "ref: refs/heads/main\tHEAD"
+			line := strings.SplitN(out, "\n", 2)[0]
+			if strings.HasPrefix(line, "ref: refs/heads/") {
+				branch = strings.Fields(strings.TrimPrefix(line, "ref: refs/heads/"))[0]
+			}
+		}
+	}
+
+	return commitSHA, branch, nil
+}
+
+// git runs a git command in dir and returns its trimmed output
+func (cm *CloneManager) git(ctx context.Context, dir string, timeout time.Duration, args ...string) (string, error) {
+	gitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(gitCtx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = nil
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 // parseRepoURL extracts org and repo name from GitHub URL
 func parseRepoURL(url string) (org, repo string, err error) {
 	// Handle various GitHub URL formats:
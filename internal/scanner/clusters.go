@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/mcpsek/mcpsek/internal/database"
+	"github.com/mcpsek/mcpsek/internal/similarity"
+)
+
+// SimilarRescanPriority is the scan queue priority given to near-duplicates of a critical tool
+const SimilarRescanPriority = 10
+
+// DescriptionCluster is a group of tools with near-duplicate descriptions
+type DescriptionCluster struct {
+	Members  []*database.IndexedTool `json:"members"`
+	Critical []string                `json:"critical"` // Names of members whose descriptions carry injection patterns
+	Servers  int                     `json:"servers"`
+}
+
+// HasCritical reports whether any member of the cluster is critical
+func (c *DescriptionCluster) HasCritical() bool {
+	return len(c.Critical) > 0
+}
+
+// DescriptionClusters groups the tool index into clusters of near-duplicate descriptions,
+// clusters with a critical member first, then largest first
+func DescriptionClusters(index []*database.IndexedTool) []*DescriptionCluster {
+	signatures := make([]similarity.Signature, len(index))
+	for i, tool := range index {
+		signatures[i] = similarity.FromInt64s(tool.MinHash)
+	}
+
+	clusters := make([]*DescriptionCluster, 0)
+	for _, members := range similarity.Cluster(signatures, similarity.DefaultThreshold) {
+		cluster := &DescriptionCluster{}
+		servers := make(map[uuid.UUID]bool)
+		for _, i := range members {
+			tool := index[i]
+			cluster.Members = append(cluster.Members, tool)
+			servers[tool.ServerID] = true
+			if tool.Description != nil && len(scanForInjection(*tool.Description)) > 0 {
+				cluster.Critical = append(cluster.Critical, tool.ServerName+"/"+tool.ToolName)
+			}
+		}
+		cluster.Servers = len(servers)
+		clusters = append(clusters, cluster)
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if clusters[i].HasCritical() != clusters[j].HasCritical() {
+			return clusters[i].HasCritical()
+		}
+		return len(clusters[i].Members) > len(clusters[j].Members)
+	})
+	return clusters
+}
+
+// flagSimilarTools flags near-duplicates of this server's critical tools on other servers
+// and queues those servers for a priority rescan; only new flags queue a rescan, or clones that
+// are all critical would keep queueing each other
+func (s *Scanner) flagSimilarTools(ctx context.Context, serverID uuid.UUID, tools []*database.ToolDefinition) error {
+	for _, tool := range tools {
+		if tool.Description == nil || tool.MinHash == nil || len(scanForInjection(*tool.Description)) == 0 {
+			continue
+		}
+
+		similar, err := s.db.GetSimilarTools(ctx, tool.ID, similarity.DefaultThreshold)
+		if err != nil {
+			return fmt.Errorf("get similar tools: %w", err)
+		}
+		for _, match := range similar {
+			if match.ServerID == serverID {
+				continue
+			}
+			created, err := s.db.FlagSimilarTool(ctx, match.ToolID, tool.ID, match.Similarity)
+			if err != nil {
+				return err
+			}
+			if !created {
+				continue
+			}
+			if err := s.db.EnqueueScan(ctx, match.ServerID, SimilarRescanPriority); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,329 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Container rule names and severities
+var containerRules = map[string]struct {
+	Severity string
+	Message  string
+}{
+	"container_privileged":      {"critical", "Container runs in privileged mode with full access to the host"},
+	"container_docker_socket":   {"critical", "Docker socket is mounted into the container, giving it control of the host"},
+	"container_host_root_mount": {"critical", "Host root filesystem is mounted into the container"},
+	"container_host_network":    {"warning", "Container shares the host network namespace"},
+	"container_root_user":       {"warning", "Container process runs as root"},
+	"container_public_port":     {"warning", "Port is published on all host interfaces"},
+	"container_exposed_port":    {"info", "Port exposed by the image"},
+}
+
+// containerRuleOrder fixes the order findings are reported in
+var containerRuleOrder = []string{
+	"container_privileged", "container_docker_socket", "container_host_root_mount",
+	"container_host_network", "container_root_user", "container_public_port", "container_exposed_port",
+}
+
+// Patterns for container configuration
+var (
+	dockerfileFromPattern   = regexp.MustCompile(`(?i)^\s*FROM\s+(\S+)`)
+	dockerfileUserPattern   = regexp.MustCompile(`(?i)^\s*USER\s+(\S+)`)
+	dockerfileExposePattern = regexp.MustCompile(`(?i)^\s*EXPOSE\s+(.+)`)
+
+	composePrivilegedPattern = regexp.MustCompile(`(?i)^\s*privileged\s*:\s*["']?true`)
+	composeNetworkPattern    = regexp.MustCompile(`(?i)^\s*network_mode\s*:\s*["']?host\b`)
+	composeUserPattern       = regexp.MustCompile(`(?i)^\s*user\s*:\s*["']?(?:root|0)(?::|["']|\s*$)`)
+	composePortPattern       = regexp.MustCompile(`^\s*-\s*["']?(?:(\d{1,3}(?:\.\d{1,3}){3}|\[[0-9a-fA-F:]*\]):)?(\d+(?:-\d+)?):(\d+(?:-\d+)?)`)
+	composeRootMountPattern  = regexp.MustCompile(`^\s*-\s*["']?/:`)
+
+	dockerRunPattern = regexp.MustCompile(`\bdocker\s+(?:container\s+)?run\b`)
+	dockerSocketPath = "/var/run/docker.sock"
+)
+
+// dockerValueFlags are `docker run` flags that take a separate value argument
+var dockerValueFlags = map[string]bool{
+	"-e": true, "--env": true, "--env-file": true, "--name": true, "-w": true, "--workdir": true,
+	"--entrypoint": true, "-l": true, "--label": true, "--restart": true, "--platform": true,
+	"-h": true, "--hostname": true, "--add-host": true, "--cap-drop": true, "-m": true,
+	"--memory": true, "--cpus": true, "--device": true, "--tmpfs": true, "--shm-size": true,
+	"--log-driver": true, "--log-opt": true, "--security-opt": true, "--pull": true,
+	"--ulimit": true, "--dns": true, "--gpus": true, "--pid": true, "--ipc": true, "--expose": true,
+}
+
+// containerScan accumulates container findings in rule order
+type containerScan struct {
+	findings map[string]*ExposureFinding
+}
+
+// add records evidence for a rule
+func (c *containerScan) add(rule string, evidence Evidence) {
+	if c.findings == nil {
+		c.findings = make(map[string]*ExposureFinding)
+	}
+	f, ok := c.findings[rule]
+	if !ok {
+		def := containerRules[rule]
+		f = &ExposureFinding{Rule: rule, Severity: def.Severity, Message: def.Message, Evidence: make([]Evidence, 0)}
+		c.findings[rule] = f
+	}
+	if len(f.Evidence) < 5 {
+		f.Evidence = append(f.Evidence, evidence)
+	}
+}
+
+// list returns the findings in rule order
+func (c *containerScan) list() []ExposureFinding {
+	result := make([]ExposureFinding, 0, len(c.findings))
+	for _, rule := range containerRuleOrder {
+		if f, ok := c.findings[rule]; ok {
+			result = append(result, *f)
+		}
+	}
+	return result
+}
+
+// CheckContainers parses Dockerfiles, compose files and `docker run` snippets in docs
+// for configurations that expose the host
+func CheckContainers(rc *RepoContext) []ExposureFinding {
+	scan := &containerScan{}
+
+	filepath.Walk(rc.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if skipDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		kind := containerFileKind(info.Name())
+		if kind == "" {
+			return nil
+		}
+
+		relPath := rc.RelPath(path)
+		fc := rc.Context(relPath)
+		// The README's run instructions are what users copy, so docs count here
+		if fc.FileClass == ClassTest || fc.FileClass == ClassVendored || fc.FileClass == ClassGenerated {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		contentStr := string(content)
+
+		switch kind {
+		case "dockerfile":
+			scan.dockerfile(contentStr, relPath, fc)
+		case "compose":
+			scan.compose(contentStr, relPath, fc)
+		case "docs":
+			scan.docs(contentStr, relPath, fc)
+		}
+		return nil
+	})
+
+	return scan.list()
+}
+
+// containerFileKind classifies a file name as "dockerfile", "compose", "docs" or ""
+func containerFileKind(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case lower == "dockerfile" || lower == "containerfile" ||
+		strings.HasPrefix(lower, "dockerfile.") || strings.HasSuffix(lower, ".dockerfile"):
+		return "dockerfile"
+	case (strings.HasPrefix(lower, "docker-compose") || strings.HasPrefix(lower, "compose")) &&
+		(strings.HasSuffix(lower, ".yml") || strings.HasSuffix(lower, ".yaml")):
+		return "compose"
+	case strings.HasSuffix(lower, ".md") || strings.HasSuffix(lower, ".mdx"):
+		return "docs"
+	}
+	return ""
+}
+
+// dockerfile checks the final stage's user and the exposed ports
+func (c *containerScan) dockerfile(content, relPath string, fc FileContext) {
+	finalFrom, finalUser := -1, -1
+	user := ""
+
+	for _, ll := range logicalLines(content) {
+		switch {
+		case dockerfileFromPattern.MatchString(ll.text):
+			// A new stage resets the user
+			finalFrom, finalUser, user = ll.offset, -1, ""
+			image := dockerfileFromPattern.FindStringSubmatch(ll.text)[1]
+			if strings.Contains(image, "nonroot") {
+				user = "nonroot"
+			}
+		case dockerfileUserPattern.MatchString(ll.text):
+			finalUser = ll.offset
+			user = dockerfileUserPattern.FindStringSubmatch(ll.text)[1]
+		case dockerfileExposePattern.MatchString(ll.text):
+			c.add("container_exposed_port", evidenceAt(content, relPath, fc, ll.offset))
+		}
+	}
+
+	if finalFrom < 0 {
+		return
+	}
+	switch {
+	case user == "":
+		c.add("container_root_user", evidenceAt(content, relPath, fc, finalFrom))
+	case isRootUser(user):
+		c.add("container_root_user", evidenceAt(content, relPath, fc, finalUser))
+	}
+}
+
+// compose checks service definitions line by line
+func (c *containerScan) compose(content, relPath string, fc FileContext) {
+	offset := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		ev := func() Evidence { return evidenceAt(content, relPath, fc, offset) }
+
+		switch {
+		case composePrivilegedPattern.MatchString(line):
+			c.add("container_privileged", ev())
+		case composeNetworkPattern.MatchString(line):
+			c.add("container_host_network", ev())
+		case composeUserPattern.MatchString(line):
+			c.add("container_root_user", ev())
+		case strings.Contains(line, dockerSocketPath):
+			c.add("container_docker_socket", ev())
+		case composeRootMountPattern.MatchString(line):
+			c.add("container_host_root_mount", ev())
+		case composePortPattern.MatchString(line):
+			if isPublicHostIP(composePortPattern.FindStringSubmatch(line)[1]) {
+				c.add("container_public_port", ev())
+			}
+		}
+		offset += len(line)
+	}
+}
+
+// docs checks `docker run` commands in documentation
+func (c *containerScan) docs(content, relPath string, fc FileContext) {
+	for _, ll := range logicalLines(content) {
+		loc := dockerRunPattern.FindStringIndex(ll.text)
+		if loc == nil {
+			continue
+		}
+		c.dockerRun(strings.Fields(ll.text[loc[1]:]), evidenceAt(content, relPath, fc, ll.offset))
+	}
+}
+
+// dockerRun checks the flags of one `docker run` command
+func (c *containerScan) dockerRun(args []string, ev Evidence) {
+	for i := 0; i < len(args); i++ {
+		flag, value, hasValue := strings.Cut(args[i], "=")
+		next := func() string {
+			if hasValue {
+				return value
+			}
+			if i+1 < len(args) {
+				i++
+				return args[i]
+			}
+			return ""
+		}
+
+		switch flag {
+		case "--privileged":
+			c.add("container_privileged", ev)
+		case "--network", "--net":
+			if next() == "host" {
+				c.add("container_host_network", ev)
+			}
+		case "-u", "--user":
+			if isRootUser(next()) {
+				c.add("container_root_user", ev)
+			}
+		case "-v", "--volume", "--mount":
+			mount := next()
+			switch {
+			case strings.Contains(mount, dockerSocketPath):
+				c.add("container_docker_socket", ev)
+			case strings.HasPrefix(mount, "/:") || strings.Contains(mount, "source=/,") || strings.Contains(mount, "src=/,"):
+				c.add("container_host_root_mount", ev)
+			}
+		case "-p", "--publish":
+			if isPublicPublish(next()) {
+				c.add("container_public_port", ev)
+			}
+		case "-P", "--publish-all":
+			c.add("container_public_port", ev)
+		case "--cap-add":
+			if cap := strings.ToUpper(next()); cap == "ALL" || cap == "SYS_ADMIN" {
+				c.add("container_privileged", ev)
+			}
+		default:
+			// The first non-flag argument is the image; its own arguments follow
+			if !strings.HasPrefix(args[i], "-") {
+				return
+			}
+			if dockerValueFlags[flag] {
+				next()
+			}
+		}
+	}
+}
+
+// logicalLine is a line with backslash continuations joined
+type logicalLine struct {
+	text   string
+	offset int // Byte offset of the first physical line
+}
+
+// logicalLines joins shell/Dockerfile line continuations
+func logicalLines(content string) []logicalLine {
+	lines := make([]logicalLine, 0)
+	var current strings.Builder
+	start, offset := 0, 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		trimmed := strings.TrimRight(line, "\r\n ")
+		if current.Len() == 0 {
+			start = offset
+		}
+		offset += len(line)
+		if strings.HasSuffix(trimmed, "\\") {
+			current.WriteString(strings.TrimSuffix(trimmed, "\\"))
+			current.WriteByte(' ')
+			continue
+		}
+		current.WriteString(trimmed)
+		lines = append(lines, logicalLine{text: current.String(), offset: start})
+		current.Reset()
+	}
+	return lines
+}
+
+// isRootUser reports whether a USER/--user value is root
+func isRootUser(user string) bool {
+	user = strings.Trim(user, `"'`)
+	name, _, _ := strings.Cut(user, ":")
+	return name == "root" || name == "0"
+}
+
+// isPublicHostIP reports whether a published port's host IP listens on all interfaces
+func isPublicHostIP(ip string) bool {
+	return ip == "" || ip == "0.0.0.0" || ip == "[::]"
+}
+
+// isPublicPublish reports whether a -p value publishes on all interfaces
+func isPublicPublish(spec string) bool {
+	spec = strings.Trim(spec, `"'`)
+	parts := strings.Split(spec, ":")
+	switch len(parts) {
+	case 1, 2:
+		return true // "8080" or "8080:8080" bind every interface
+	default:
+		return isPublicHostIP(strings.Join(parts[:len(parts)-2], ":"))
+	}
+}
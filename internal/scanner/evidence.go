@@ -0,0 +1,41 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Evidence points at the file and line that proved a result
+type Evidence struct {
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+	Snippet  string `json:"snippet"`
+	FileContext
+}
+
+// findEvidence returns up to limit locations where pattern matches content
+func findEvidence(content, relPath string, fc FileContext, pattern *regexp.Regexp, limit int) []Evidence {
+	evidence := make([]Evidence, 0)
+	for _, loc := range pattern.FindAllStringIndex(content, limit) {
+		evidence = append(evidence, evidenceAt(content, relPath, fc, loc[0]))
+	}
+	return evidence
+}
+
+// evidenceAt builds evidence for the line containing byte offset
+func evidenceAt(content, relPath string, fc FileContext, offset int) Evidence {
+	lineStart := strings.LastIndexByte(content[:offset], '\n') + 1
+	lineEnd := strings.IndexByte(content[offset:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(content)
+	} else {
+		lineEnd += offset
+	}
+
+	return Evidence{
+		FilePath:    relPath,
+		Line:        strings.Count(content[:offset], "\n") + 1,
+		Snippet:     truncate(strings.TrimSpace(content[lineStart:lineEnd]), 200),
+		FileContext: fc,
+	}
+}
@@ -10,30 +10,53 @@ import (
 
 // ExposureResult represents the results of endpoint exposure checking
 type ExposureResult struct {
-	Status        string `json:"status"`         // "pass", "warning", "critical"
-	Transport     string `json:"transport"`      // "stdio", "sse", "http", "websocket", "unknown"
-	BindAddress   string `json:"bind_address"`   // "127.0.0.1", "0.0.0.0", or empty
-	TLSConfigured *bool  `json:"tls_configured,omitempty"`
-	DefaultPort   *int   `json:"default_port,omitempty"`
+	Status            string              `json:"status"`     // "pass", "warning", "critical"
+	Transport         string              `json:"transport"`  // Primary transport: the most exposed one in Transports, or "unknown"
+	Transports        []string            `json:"transports"` // "streamable_http", "sse", "websocket", "http", "stdio"
+	TransportEvidence []TransportEvidence `json:"transport_evidence,omitempty"`
+	BindAddress       string              `json:"bind_address"` // "127.0.0.1", "0.0.0.0", or empty
+	TLSConfigured     *bool               `json:"tls_configured,omitempty"`
+	DefaultPort       *int                `json:"default_port,omitempty"`
+	Indicators        []ExposureIndicator `json:"indicators,omitempty"`
+	Findings          []ExposureFinding   `json:"findings,omitempty"` // HTTP transport, container and client config rules
+	InstallCommand    *InstallCommand     `json:"install_command,omitempty"`
+}
+
+// TransportEvidence records the file and line that proved a transport
+type TransportEvidence struct {
+	Transport string `json:"transport"`
+	Counted   bool   `json:"counted"` // Whether the location was trusted enough to count
+	Evidence
+}
+
+// ExposureIndicator records where an exposure indicator was seen
+type ExposureIndicator struct {
+	Indicator string `json:"indicator"` // "bind_all", "bind_localhost", "tls"
+	FilePath  string `json:"file_path"`
+	Counted   bool   `json:"counted"` // Whether the location was trusted enough to affect status
+	FileContext
 }
 
 // CheckExposure scans a repository for endpoint exposure risks
-func CheckExposure(repoPath string) (*ExposureResult, error) {
+func CheckExposure(rc *RepoContext) (*ExposureResult, error) {
 	result := &ExposureResult{
-		Status:    "pass",
-		Transport: "unknown",
+		Status:            "pass",
+		Transport:         "unknown",
+		Transports:        make([]string, 0),
+		TransportEvidence: make([]TransportEvidence, 0),
+		Indicators:        make([]ExposureIndicator, 0),
 	}
 
-	// Track transport type indicators
-	stdioCount := 0
-	networkCount := 0
+	// Track transports seen in trusted locations
+	transports := make(map[string]bool)
 	hasBindAll := false
 	hasBindLocalhost := false
 	hasTLS := false
 	var detectedPort *int
+	httpSec := &httpSecurityScan{}
 
 	// Scan all files
-	err := filepath.Walk(repoPath, func(path string, info os.FileInfo, err error) error {
+	err := filepath.Walk(rc.Path, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
@@ -58,40 +81,75 @@ func CheckExposure(repoPath string) (*ExposureResult, error) {
 		}
 
 		contentStr := string(content)
+		relativePath := rc.RelPath(path)
+		fc := rc.Context(relativePath)
+		counts := rc.Counts(fc)
+
+		record := func(indicator string) {
+			result.Indicators = append(result.Indicators, ExposureIndicator{
+				Indicator:   indicator,
+				FilePath:    relativePath,
+				Counted:     counts,
+				FileContext: fc,
+			})
+		}
 
-		// Check for stdio indicators
-		for _, pattern := range stdioPatterns {
-			if pattern.MatchString(contentStr) {
-				stdioCount++
+		// Check each SDK transport class, keeping the first match per transport and file
+		for _, tp := range transportPatterns {
+			for _, pattern := range tp.Patterns {
+				loc := pattern.FindStringIndex(contentStr)
+				if loc == nil {
+					continue
+				}
+				result.TransportEvidence = append(result.TransportEvidence, TransportEvidence{
+					Transport: tp.Transport,
+					Counted:   counts,
+					Evidence:  evidenceAt(contentStr, relativePath, fc, loc[0]),
+				})
+				transports[tp.Transport] = transports[tp.Transport] || counts
 				break
 			}
 		}
 
-		// Check for network transport indicators
-		for _, pattern := range networkTransportPatterns {
-			if pattern.MatchString(contentStr) {
-				networkCount++
-				break
+		// Plain HTTP servers
+		for _, pattern := range httpServerPatterns {
+			loc := pattern.FindStringIndex(contentStr)
+			if loc == nil {
+				continue
 			}
+			result.TransportEvidence = append(result.TransportEvidence, TransportEvidence{
+				Transport: "http",
+				Counted:   counts,
+				Evidence:  evidenceAt(contentStr, relativePath, fc, loc[0]),
+			})
+			transports["http"] = transports["http"] || counts
+			break
 		}
 
 		// Check bind address
 		if bindAllPattern.MatchString(contentStr) {
-			hasBindAll = true
+			record("bind_all")
+			hasBindAll = hasBindAll || counts
 		}
 		if bindLocalhostPattern.MatchString(contentStr) {
-			hasBindLocalhost = true
+			record("bind_localhost")
+			hasBindLocalhost = hasBindLocalhost || counts
 		}
 
 		// Check TLS
 		for _, pattern := range tlsPatterns {
 			if pattern.MatchString(contentStr) {
-				hasTLS = true
+				record("tls")
+				hasTLS = hasTLS || counts
 				break
 			}
 		}
 
-		// Extract port
+		// Extract port and HTTP transport rule evidence from trusted locations only
+		if !counts {
+			return nil
+		}
+		httpSec.scan(contentStr, relativePath, fc)
 		if matches := portPattern.FindStringSubmatch(contentStr); len(matches) > 0 {
 			for i := 1; i < len(matches); i++ {
 				if matches[i] != "" {
@@ -110,18 +168,24 @@ func CheckExposure(repoPath string) (*ExposureResult, error) {
 		return nil, fmt.Errorf("walk repository: %w", err)
 	}
 
-	// Determine transport type
-	if networkCount > stdioCount {
-		// Network transport detected
-		if networkCount > 0 {
-			result.Transport = "http" // Generic HTTP/SSE
+	// A plain HTTP server only counts as a transport when no SDK transport class was found;
+	// otherwise it's an auxiliary server (OAuth callback, health check) or serves the SDK transport
+	if transports["streamable_http"] || transports["sse"] || transports["websocket"] || transports["stdio"] {
+		delete(transports, "http")
+	}
+
+	// Transports in order of exposure; the first is the primary transport
+	for _, t := range transportOrder {
+		if transports[t] {
+			result.Transports = append(result.Transports, t)
 		}
-	} else if stdioCount > 0 {
-		result.Transport = "stdio"
+	}
+	if len(result.Transports) > 0 {
+		result.Transport = result.Transports[0]
 	}
 
 	// Set bind address if network transport
-	if result.Transport == "http" || result.Transport == "sse" || result.Transport == "websocket" {
+	if result.IsNetwork() {
 		if hasBindAll {
 			result.BindAddress = "0.0.0.0"
 		} else if hasBindLocalhost {
@@ -132,9 +196,7 @@ func CheckExposure(repoPath string) (*ExposureResult, error) {
 	}
 
 	// Determine status
-	if result.Transport == "stdio" {
-		result.Status = "pass"
-	} else if result.Transport == "http" || result.Transport == "sse" || result.Transport == "websocket" {
+	if result.IsNetwork() {
 		// Network transport - check for risks
 		if hasBindAll && !hasTLS {
 			result.Status = "critical" // Exposed to internet without TLS
@@ -143,11 +205,39 @@ func CheckExposure(repoPath string) (*ExposureResult, error) {
 		} else {
 			result.Status = "pass" // Localhost + TLS
 		}
+
+		// Origin validation, CORS and session handling
+		result.Findings = append(result.Findings, httpSec.findings(result)...)
+	}
+
+	// Dockerfiles, compose files and documented `docker run` commands
+	result.Findings = append(result.Findings, CheckContainers(rc)...)
+
+	// Client config snippets users copy from the README
+	clientFindings, install := CheckClientConfigs(rc)
+	result.Findings = mergeExposureFindings(result.Findings, clientFindings...)
+	result.InstallCommand = install
+
+	for _, finding := range result.Findings {
+		result.Status = escalateStatus(result.Status, finding.Severity)
 	}
 
 	return result, nil
 }
 
+// transportOrder lists transports from most to least exposed
+var transportOrder = []string{"streamable_http", "sse", "websocket", "http", "stdio"}
+
+// IsNetwork reports whether any detected transport is reachable over the network
+func (r *ExposureResult) IsNetwork() bool {
+	for _, t := range r.Transports {
+		if t != "stdio" {
+			return true
+		}
+	}
+	return false
+}
+
 // ToJSON converts ExposureResult to JSON
 func (r *ExposureResult) ToJSON() (json.RawMessage, error) {
 	data, err := json.Marshal(r)
@@ -0,0 +1,229 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+// Finding is one warning or critical issue a scan reported, identified across scans by its fingerprint
+type Finding struct {
+	Fingerprint string `json:"fingerprint"`
+	Check       string `json:"check"` // "tool_integrity", "auth" or "exposure"
+	Rule        string `json:"rule"`
+	Severity    string `json:"severity"` // "warning" or "critical"
+	ToolName    string `json:"tool_name,omitempty"`
+	FilePath    string `json:"file_path,omitempty"`
+	Line        int    `json:"line,omitempty"`
+	Message     string `json:"message"`
+}
+
+// measuredRules report a measurement in their snippet, which changes between scans without the
+// finding changing, so they're identified by tool alone
+var measuredRules = map[string]bool{
+	"long_description": true,
+	"classifier":       true,
+}
+
+// findingSet collects findings, keeping the first of any with the same fingerprint
+type findingSet struct {
+	findings []Finding
+	seen     map[string]bool
+}
+
+// add fingerprints a finding from its check, rule and identity and adds it if new
+// Identity leaves out line numbers, so a finding survives unrelated edits to its file
+func (s *findingSet) add(f Finding, identity ...string) {
+	if f.Severity != "warning" && f.Severity != "critical" {
+		return
+	}
+
+	key := strings.Join(append([]string{f.Check, f.Rule}, identity...), "\x00")
+	f.Fingerprint = fmt.Sprintf("%x", sha256.Sum256([]byte(key)))[:32]
+	if s.seen[f.Fingerprint] {
+		return
+	}
+	s.seen[f.Fingerprint] = true
+	s.findings = append(s.findings, f)
+}
+
+// CollectFindings flattens a scan's results into fingerprinted findings
+// Only findings in locations trusted enough to affect a check's status are included
+func CollectFindings(rc *RepoContext, result *ScanResult) []Finding {
+	set := &findingSet{findings: make([]Finding, 0), seen: make(map[string]bool)}
+
+	if integrity := result.IntegrityResult; integrity != nil {
+		for _, list := range [][]IntegrityFinding{
+			integrity.HiddenInstructions, integrity.SuspiciousParameters, integrity.LongDescriptions,
+			integrity.CrossToolReferences, integrity.SamplingInjections, integrity.CredentialElicitations,
+			integrity.OutputInjections, integrity.ClassifiedInjections,
+		} {
+			for _, f := range list {
+				if !rc.Counts(f.FileContext) {
+					continue
+				}
+				// Findings in a tool's description belong to the tool; code findings to their file
+				identity := []string{f.ToolName, f.Snippet}
+				if f.Line > 0 {
+					identity = []string{f.FilePath, f.Snippet}
+				} else if measuredRules[f.PatternMatched] {
+					identity = []string{f.ToolName}
+				}
+				set.add(Finding{
+					Check:    "tool_integrity",
+					Rule:     f.PatternMatched,
+					Severity: f.Severity,
+					ToolName: f.ToolName,
+					FilePath: f.FilePath,
+					Line:     f.Line,
+					Message:  f.Snippet,
+				}, identity...)
+			}
+		}
+
+		for _, t := range integrity.MutableTools {
+			if !rc.Counts(t.FileContext) {
+				continue
+			}
+			set.add(Finding{
+				Check:    "tool_integrity",
+				Rule:     "mutable_" + t.Field,
+				Severity: "warning",
+				ToolName: t.ToolName,
+				FilePath: t.FilePath,
+				Line:     t.Line,
+				Message:  fmt.Sprintf("Tool %s is decided at runtime from %s", t.Field, t.Source),
+			}, t.ToolName, t.Source, t.FilePath)
+		}
+
+		for _, c := range integrity.Shadowing {
+			if !rc.Counts(c.FileContext) {
+				continue
+			}
+			set.add(Finding{
+				Check:    "tool_integrity",
+				Rule:     c.Kind,
+				Severity: c.Severity,
+				ToolName: c.ToolName,
+				Message:  c.Message,
+			}, c.ToolName, c.Target.ServerID.String(), c.Target.ToolName)
+		}
+	}
+
+	if auth := result.AuthResult; auth != nil {
+		switch {
+		case auth.Method == "none":
+			set.add(Finding{Check: "auth", Rule: "no_auth", Severity: "critical", Message: "No authentication detected"})
+		case auth.Method == "static_key":
+			set.add(Finding{Check: "auth", Rule: "static_key", Severity: "warning", Message: "Authenticates with a static API key"})
+		case auth.Method == "oauth2" && (!*auth.TokenRefresh || !*auth.ScopedPermissions):
+			set.add(Finding{Check: "auth", Rule: "oauth_incomplete", Severity: "warning", Message: "OAuth without token refresh or scoped permissions"})
+		}
+
+		for _, s := range auth.CommittedSecrets {
+			if !rc.Confident(s.FileContext) {
+				continue
+			}
+			set.add(Finding{
+				Check:    "auth",
+				Rule:     "committed_secret",
+				Severity: "critical",
+				FilePath: s.FilePath,
+				Line:     s.LineNumber,
+				Message:  fmt.Sprintf("%s committed: %s", s.SecretType, s.Snippet),
+			}, s.FilePath, s.SecretType, s.Snippet)
+		}
+
+		for _, s := range auth.HistorySecrets {
+			if s.StillAtHead || !rc.Confident(s.FileContext) {
+				continue
+			}
+			severity := "warning"
+			if s.Validated {
+				severity = "critical"
+			}
+			set.add(Finding{
+				Check:    "auth",
+				Rule:     "history_secret",
+				Severity: severity,
+				FilePath: s.FilePath,
+				Line:     s.LineNumber,
+				Message:  fmt.Sprintf("%s in commit %s: %s", s.SecretType, truncate(s.CommitSHA, 12), s.Snippet),
+			}, s.CommitSHA, s.FilePath, s.SecretType, s.Snippet)
+		}
+
+		for _, analysis := range auth.ScopeAnalysis {
+			capabilities := make(map[string]string)
+			for _, rs := range analysis.RequestedScopes {
+				capabilities[rs.Scope] = rs.Capability
+			}
+			for _, scope := range analysis.ExcessScopes {
+				severity := "warning"
+				if capabilityRank[capabilities[scope]] >= capabilityRank[CapabilityDelete] {
+					severity = "critical"
+				}
+				set.add(Finding{
+					Check:    "auth",
+					Rule:     "excess_scope",
+					Severity: severity,
+					Message:  fmt.Sprintf("%s scope %s exceeds what the tools need (%s)", analysis.Provider, scope, analysis.ToolCapability),
+				}, analysis.Provider, scope)
+			}
+		}
+
+		if auth.Conformance != nil {
+			for _, req := range auth.Conformance.Requirements {
+				if req.Status != ConformanceFail {
+					continue
+				}
+				set.add(Finding{
+					Check:    "auth",
+					Rule:     "auth_spec_" + req.ID,
+					Severity: req.Severity,
+					Message:  req.Title,
+				}, req.ID)
+			}
+		}
+	}
+
+	if exposure := result.ExposureResult; exposure != nil {
+		if exposure.IsNetwork() {
+			bindAll := exposure.BindAddress == "0.0.0.0"
+			tls := exposure.TLSConfigured != nil && *exposure.TLSConfigured
+			switch {
+			case bindAll && !tls:
+				set.add(Finding{Check: "exposure", Rule: "exposed_without_tls", Severity: "critical", Message: "Binds to all interfaces without TLS"})
+			case bindAll:
+				set.add(Finding{Check: "exposure", Rule: "bind_all", Severity: "warning", Message: "Binds to all interfaces"})
+			case !tls:
+				set.add(Finding{Check: "exposure", Rule: "no_tls", Severity: "warning", Message: "Network transport without TLS"})
+			}
+		}
+
+		// Each rule's evidence is merged into one finding, so the rule alone identifies it
+		for _, f := range exposure.Findings {
+			finding := Finding{Check: "exposure", Rule: f.Rule, Severity: f.Severity, Message: f.Message}
+			counted := len(f.Evidence) == 0
+			for _, ev := range f.Evidence {
+				if exposureCounts(rc, ev.FileContext) {
+					finding.FilePath = ev.FilePath
+					finding.Line = ev.Line
+					counted = true
+					break
+				}
+			}
+			if !counted {
+				continue
+			}
+			set.add(finding)
+		}
+	}
+
+	return set.findings
+}
+
+// exposureCounts reports whether exposure evidence is trusted enough to record as a finding
+// The container and client config rules read the README's instructions on purpose, so docs count
+func exposureCounts(rc *RepoContext, fc FileContext) bool {
+	return fc.FileClass == ClassDocs || rc.Counts(fc)
+}
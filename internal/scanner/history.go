@@ -0,0 +1,199 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default limits for history scanning
+const (
+	DefaultHistoryMaxCommits = 1000
+	DefaultHistoryMaxBytes   = 100 * 1024 * 1024
+)
+
+// HistorySecretFinding is a secret added in a past commit
+type HistorySecretFinding struct {
+	SecretFinding
+	CommitSHA   string    `json:"commit_sha"`
+	AuthorDate  time.Time `json:"author_date"`
+	StillAtHead bool      `json:"still_at_head"`
+}
+
+// HistoryScanInfo describes how much history was examined
+type HistoryScanInfo struct {
+	CommitsScanned int   `json:"commits_scanned"`
+	BytesScanned   int64 `json:"bytes_scanned"`
+	Truncated      bool  `json:"truncated"` // Stopped at the commit or size limit
+}
+
+// HistoryLimits bounds a history scan
+type HistoryLimits struct {
+	MaxCommits int
+	MaxBytes   int64
+}
+
+// historyCommitMarker prefixes commit headers in the git log output (written by %x00 in the format)
+const historyCommitMarker = "\x00commit "
+
+// CheckHistory runs secret detection over every line added in the repository history
+// The clone must already have been deepened with CloneManager.FetchHistory
+func CheckHistory(ctx context.Context, rc *RepoContext, limits HistoryLimits) ([]HistorySecretFinding, *HistoryScanInfo, error) {
+	if limits.MaxCommits <= 0 {
+		limits.MaxCommits = DefaultHistoryMaxCommits
+	}
+	if limits.MaxBytes <= 0 {
+		limits.MaxBytes = DefaultHistoryMaxBytes
+	}
+
+	headSecrets := collectHeadSecrets(rc)
+
+	logCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	cmd := exec.CommandContext(logCtx, "git", "log", "-p", "--no-color", "--no-merges", "--no-ext-diff",
+		"-U0", "--format=%x00commit %H %aI", "-n", strconv.Itoa(limits.MaxCommits))
+	cmd.Dir = rc.Path
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("git log pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("start git log: %w", err)
+	}
+
+	findings, info := parseHistoryLog(stdout, rc, headSecrets, limits.MaxBytes)
+
+	// Stop git if we hit the size limit before it finished writing; otherwise it must have succeeded,
+	// or an empty log would pass as a clean history
+	if info.Truncated {
+		cmd.Process.Kill()
+		cmd.Wait()
+	} else if err := cmd.Wait(); err != nil {
+		return nil, nil, fmt.Errorf("git log failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	if info.CommitsScanned >= limits.MaxCommits {
+		info.Truncated = true
+	}
+
+	return findings, info, nil
+}
+
+// parseHistoryLog reads `git log -p -U0` output and reports secrets on added lines
+// Each secret is reported once, attributed to the oldest commit that added it
+func parseHistoryLog(r io.Reader, rc *RepoContext, headSecrets map[string]bool, maxBytes int64) ([]HistorySecretFinding, *HistoryScanInfo) {
+	info := &HistoryScanInfo{}
+	bySecret := make(map[string]*HistorySecretFinding)
+	order := make([]string, 0)
+
+	var commitSHA, filePath string
+	var authorDate time.Time
+	lineNum := 0
+
+	reader := bufio.NewReaderSize(r, 64*1024)
+	for {
+		raw, err := reader.ReadString('\n')
+		info.BytesScanned += int64(len(raw))
+		if info.BytesScanned > maxBytes {
+			info.BytesScanned = maxBytes
+			info.Truncated = true
+			break
+		}
+		line := strings.TrimSuffix(raw, "\n")
+
+		switch {
+		case strings.HasPrefix(line, historyCommitMarker):
+			fields := strings.Fields(strings.TrimPrefix(line, historyCommitMarker))
+			if len(fields) == 2 {
+				commitSHA = fields[0]
+				authorDate, _ = time.Parse(time.RFC3339, fields[1])
+				info.CommitsScanned++
+			}
+			filePath = ""
+		case strings.HasPrefix(line, "+++ "):
+			filePath = strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+			if filePath == "/dev/null" {
+				filePath = ""
+			}
+		case strings.HasPrefix(line, "@@ "):
+			lineNum = parseHunkStart(line)
+		case strings.HasPrefix(line, "+") && filePath != "" && commitSHA != "":
+			for _, match := range detectSecrets(line[1:]) {
+				key := match.Value
+				finding := HistorySecretFinding{
+					SecretFinding: match.finding(filePath, lineNum),
+					CommitSHA:     commitSHA,
+					AuthorDate:    authorDate,
+					StillAtHead:   headSecrets[key],
+				}
+				finding.FileContext = rc.Context(filePath)
+
+				// git log lists newest first, so later sightings are older commits
+				if _, exists := bySecret[key]; !exists {
+					order = append(order, key)
+				}
+				bySecret[key] = &finding
+			}
+			lineNum++
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	findings := make([]HistorySecretFinding, 0, len(order))
+	for _, key := range order {
+		findings = append(findings, *bySecret[key])
+	}
+	return findings, info
+}
+
+// parseHunkStart returns the first new-file line number from a "@@ -a,b +c,d @@" header
+func parseHunkStart(header string) int {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return 0
+	}
+	start := strings.SplitN(strings.TrimPrefix(fields[2], "+"), ",", 2)[0]
+	n, _ := strconv.Atoi(start)
+	return n
+}
+
+// collectHeadSecrets returns the raw values of every secret in the working tree, kept in memory only
+func collectHeadSecrets(rc *RepoContext) map[string]bool {
+	values := make(map[string]bool)
+	filepath.Walk(rc.Path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if skipDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		content, err := os.ReadFile(p)
+		if err != nil || isBinaryContent(string(content)) {
+			return nil
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			for _, match := range detectSecrets(line) {
+				values[match.Value] = true
+			}
+		}
+		return nil
+	})
+	return values
+}
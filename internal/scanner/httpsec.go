@@ -0,0 +1,131 @@
+package scanner
+
+import "regexp"
+
+// ExposureFinding is a structured exposure finding from the HTTP transport, container or client config rules
+type ExposureFinding struct {
+	Rule     string     `json:"rule"`     // e.g. "missing_origin_validation", "container_privileged"
+	Severity string     `json:"severity"` // "info", "warning", "critical"
+	Message  string     `json:"message"`
+	Evidence []Evidence `json:"evidence,omitempty"`
+}
+
+// Patterns for HTTP transport security rules
+var (
+	// Origin or Host header validation, the MCP spec's DNS rebinding defence
+	originValidationPattern = regexp.MustCompile(`(?i)allowedOrigins|allowed_origins|enableDnsRebindingProtection|allowedHosts|allowed_hosts|TrustedHostMiddleware|TransportSecuritySettings|dns_rebinding|headers\.origin|headers\[["']origin["']\]|headers\.get\(\s*["'](?:origin|host)["']|header\(\s*["'](?:origin|host)["']\s*\)|checkOrigin|verifyClient|CheckOrigin|hostValidation|validateOrigin|validate_origin`)
+
+	// CORS allowing any origin, or reflecting the request origin
+	corsWildcardPattern = regexp.MustCompile(`(?i)Access-Control-Allow-Origin["']?\s*[,:]\s*["']\*["']|origin\s*:\s*(?:["']\*["']|true\b)|allow_origins\s*=\s*\[\s*["']\*["']\s*\]|\bcors\(\s*\)|AllowedOrigins:\s*\[\]string\{\s*"\*"\s*\}|AllowAllOrigins:\s*true`)
+
+	// CORS credentials
+	corsCredentialsPattern = regexp.MustCompile(`(?i)Access-Control-Allow-Credentials["']?\s*[,:]\s*["']?true|credentials\s*:\s*true|allow_credentials\s*=\s*True|AllowCredentials:\s*true`)
+
+	// Session creation on the Streamable HTTP transport
+	sessionCreationPattern = regexp.MustCompile(`(?i)sessionIdGenerator|onsessioninitialized|StreamableHTTPSessionManager|mcp-session-id|stateless\s*=\s*False`)
+
+	// Authentication in front of the transport
+	httpAuthPattern = regexp.MustCompile(`(?i)requireBearerAuth|authMiddleware|passport\.authenticate|verifyAccessToken|TokenVerifier|BearerAuthBackend|AuthenticationMiddleware|auth_server_provider|headers\.authorization|headers\[["']authorization["']\]|headers\.get\(\s*["']authorization["']|WithHTTPAuth|RequireBearerToken`)
+
+	// Session IDs derived from time, counters or non-cryptographic randomness
+	predictableSessionPattern = regexp.MustCompile(`(?i)(?:sessionIdGenerator|session_?id\w*)\s*[:=][^\n]{0,100}(?:Math\.random|Date\.now|new Date\(|\+\+|\bcounter\b|uuid1\(|uuid\.v1\(|v1\(\)|randint\(|random\.random\(|time\.time\(|time\.Now\(\)\.Unix|rand\.Int)`)
+)
+
+// mergeExposureFindings appends findings, folding evidence into an existing finding with the same rule
+func mergeExposureFindings(findings []ExposureFinding, more ...ExposureFinding) []ExposureFinding {
+	for _, f := range more {
+		merged := false
+		for i := range findings {
+			if findings[i].Rule == f.Rule {
+				findings[i].Evidence = append(findings[i].Evidence, f.Evidence...)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// httpSecurityScan accumulates evidence for the HTTP transport rules across files
+type httpSecurityScan struct {
+	originValidated bool
+	authenticated   bool
+	corsCredentials []Evidence
+	sessions        []Evidence
+	predictable     []Evidence
+}
+
+// scan records the rule evidence in one trusted file
+func (h *httpSecurityScan) scan(content, relPath string, fc FileContext) {
+	h.originValidated = h.originValidated || originValidationPattern.MatchString(content)
+	h.authenticated = h.authenticated || httpAuthPattern.MatchString(content)
+
+	// Wildcard CORS is only critical together with credentials in the same file
+	if corsCredentialsPattern.MatchString(content) {
+		wildcard := findEvidence(content, relPath, fc, corsWildcardPattern, 3)
+		if len(wildcard) > 0 {
+			h.corsCredentials = append(h.corsCredentials, wildcard...)
+			h.corsCredentials = append(h.corsCredentials, findEvidence(content, relPath, fc, corsCredentialsPattern, 1)...)
+		}
+	}
+
+	h.sessions = append(h.sessions, findEvidence(content, relPath, fc, sessionCreationPattern, 3)...)
+	h.predictable = append(h.predictable, findEvidence(content, relPath, fc, predictableSessionPattern, 3)...)
+}
+
+// findings evaluates the rules for a network-transport server
+func (h *httpSecurityScan) findings(result *ExposureResult) []ExposureFinding {
+	findings := make([]ExposureFinding, 0)
+
+	if !h.originValidated {
+		// Point at the places the network transport is created
+		evidence := make([]Evidence, 0)
+		for _, te := range result.TransportEvidence {
+			if te.Counted && te.Transport != "stdio" {
+				evidence = append(evidence, te.Evidence)
+			}
+		}
+		findings = append(findings, ExposureFinding{
+			Rule:     "missing_origin_validation",
+			Severity: "warning",
+			Message:  "No Origin or Host header validation found; any website can drive the server through DNS rebinding",
+			Evidence: evidence,
+		})
+	}
+
+	if len(h.corsCredentials) > 0 {
+		findings = append(findings, ExposureFinding{
+			Rule:     "cors_wildcard_credentials",
+			Severity: "critical",
+			Message:  "CORS allows any origin while allowing credentials",
+			Evidence: h.corsCredentials,
+		})
+	}
+
+	if len(h.sessions) > 0 && !h.authenticated {
+		severity := "warning"
+		if result.BindAddress == "0.0.0.0" {
+			severity = "critical"
+		}
+		findings = append(findings, ExposureFinding{
+			Rule:     "unauthenticated_session",
+			Severity: severity,
+			Message:  "Sessions are created without authenticating the client",
+			Evidence: h.sessions,
+		})
+	}
+
+	if len(h.predictable) > 0 {
+		findings = append(findings, ExposureFinding{
+			Rule:     "predictable_session_id",
+			Severity: "warning",
+			Message:  "Mcp-Session-Id is derived from time, a counter or non-cryptographic randomness",
+			Evidence: h.predictable,
+		})
+	}
+
+	return findings
+}
@@ -0,0 +1,58 @@
+package scanner
+
+import (
+	"fmt"
+
+	"github.com/mcpsek/mcpsek/internal/classifier"
+)
+
+// DefaultClassifierThreshold is the injection probability that raises a warning
+const DefaultClassifierThreshold = 0.6
+
+// InjectionScore is the classifier's prompt-injection probability for a tool description
+type InjectionScore struct {
+	ToolName    string  `json:"tool_name"`
+	Probability float64 `json:"probability"`
+	FilePath    string  `json:"file_path,omitempty"`
+}
+
+// AddInjectionScores scores every tool description with the classifier, alongside the regex
+// rules, and raises a warning for descriptions at or above threshold
+func (r *IntegrityResult) AddInjectionScores(rc *RepoContext, model *classifier.Model, threshold float64, tools []*ToolDefinition) {
+	if model == nil {
+		return
+	}
+	if threshold <= 0 {
+		threshold = DefaultClassifierThreshold
+	}
+
+	r.InjectionScores = make([]InjectionScore, 0, len(tools))
+	r.ClassifiedInjections = make([]IntegrityFinding, 0)
+	for _, tool := range tools {
+		if tool.Description == "" {
+			continue
+		}
+
+		probability := model.Probability(tool.Description)
+		r.InjectionScores = append(r.InjectionScores, InjectionScore{
+			ToolName:    tool.Name,
+			Probability: probability,
+			FilePath:    tool.SourceFile,
+		})
+		if probability < threshold {
+			continue
+		}
+
+		r.ClassifiedInjections = append(r.ClassifiedInjections, IntegrityFinding{
+			ToolName:       tool.Name,
+			PatternMatched: "classifier",
+			Snippet:        fmt.Sprintf("Injection probability %.2f: %s", probability, truncate(tool.Description, 200)),
+			Severity:       "warning",
+			FilePath:       tool.SourceFile,
+			FileContext:    tool.FileContext,
+		})
+		if rc.Counts(tool.FileContext) {
+			r.Status = escalateStatus(r.Status, "warning")
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/mcpsek/mcpsek/internal/classifier"
+)
+
+// CheckObservedTool runs the integrity rules over a tool as a client received it, rather than as
+// found in source; parameter descriptions get the injection rules too, since the model reads them
+func CheckObservedTool(tool ObservedTool, model *classifier.Model, threshold float64) []IntegrityFinding {
+	var schema struct {
+		Properties map[string]struct {
+			Description string `json:"description"`
+		} `json:"properties"`
+	}
+	json.Unmarshal(tool.InputSchema, &schema) // A schema that doesn't parse has no parameters to check
+
+	definition := &ToolDefinition{Name: tool.Name, Description: tool.Description}
+	names := make([]string, 0, len(schema.Properties))
+	if len(schema.Properties) > 0 {
+		definition.Parameters = make(map[string]interface{}, len(schema.Properties))
+		for name, param := range schema.Properties {
+			definition.Parameters[name] = param.Description
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	result := &IntegrityResult{}
+	scanToolForPoison(definition, result)
+	findings := make([]IntegrityFinding, 0)
+	for _, list := range [][]IntegrityFinding{
+		result.HiddenInstructions, result.SuspiciousParameters, result.LongDescriptions, result.CrossToolReferences,
+	} {
+		findings = append(findings, list...)
+	}
+
+	for _, name := range names {
+		for _, match := range scanForInjection(schema.Properties[name].Description) {
+			findings = append(findings, IntegrityFinding{
+				ToolName:       tool.Name,
+				PatternMatched: match.Pattern,
+				Snippet:        fmt.Sprintf("Parameter %s: %s", name, match.Snippet),
+				Severity:       "critical",
+				Language:       match.Language,
+			})
+		}
+	}
+
+	if finding := classifyText(model, threshold, tool.Name, tool.Description); finding != nil {
+		findings = append(findings, *finding)
+	}
+
+	return findings
+}
+
+// CheckText runs the injection rules over other text a server puts in front of the model, such as
+// tool results and prompts, and the classifier when a model is given
+func CheckText(name, text string, model *classifier.Model, threshold float64) []IntegrityFinding {
+	findings := make([]IntegrityFinding, 0)
+	for _, match := range scanForInjection(text) {
+		findings = append(findings, IntegrityFinding{
+			ToolName:       name,
+			PatternMatched: match.Pattern,
+			Snippet:        match.Snippet,
+			Severity:       "critical",
+			Language:       match.Language,
+		})
+	}
+
+	if finding := classifyText(model, threshold, name, text); finding != nil {
+		findings = append(findings, *finding)
+	}
+
+	return findings
+}
+
+// classifyText returns a classifier warning for text at or above threshold, or nil
+func classifyText(model *classifier.Model, threshold float64, name, text string) *IntegrityFinding {
+	if model == nil || text == "" {
+		return nil
+	}
+	if threshold <= 0 {
+		threshold = DefaultClassifierThreshold
+	}
+
+	probability := model.Probability(text)
+	if probability < threshold {
+		return nil
+	}
+	return &IntegrityFinding{
+		ToolName:       name,
+		PatternMatched: "classifier",
+		Snippet:        fmt.Sprintf("Injection probability %.2f: %s", probability, truncate(text, 200)),
+		Severity:       "warning",
+	}
+}
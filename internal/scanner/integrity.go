@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 )
 
 // IntegrityResult represents the results of tool integrity checking
@@ -16,6 +18,20 @@ type IntegrityResult struct {
 	SuspiciousParameters []IntegrityFinding `json:"suspicious_parameters,omitempty"`
 	LongDescriptions     []IntegrityFinding `json:"long_descriptions,omitempty"`
 	CrossToolReferences  []IntegrityFinding `json:"cross_tool_references,omitempty"`
+	// Sampling and elicitation requests the server sends to the client
+	ServerRequests         []ServerRequest    `json:"server_requests,omitempty"`
+	SamplingInjections     []IntegrityFinding `json:"sampling_injections,omitempty"`
+	CredentialElicitations []IntegrityFinding `json:"credential_elicitations,omitempty"`
+	// Instructions injected through the text a tool returns rather than its description
+	OutputInjections []IntegrityFinding `json:"output_injections,omitempty"`
+	// Definitions decided at runtime, which make the stored hashes meaningless
+	MutableTools []MutableTool `json:"mutable_tools,omitempty"`
+	MutationRisk string        `json:"mutation_risk"` // "static" or "runtime"
+	// Tools that shadow or reference popular servers' tools, from the ecosystem-wide index
+	Shadowing []ToolCollision `json:"shadowing,omitempty"`
+	// Statistical classifier scores per description, for paraphrases the regex rules miss
+	InjectionScores      []InjectionScore   `json:"injection_scores,omitempty"`
+	ClassifiedInjections []IntegrityFinding `json:"classified_injections,omitempty"`
 }
 
 // IntegrityFinding represents a specific finding
@@ -24,6 +40,10 @@ type IntegrityFinding struct {
 	PatternMatched string `json:"pattern_matched"`
 	Snippet        string `json:"snippet"`
 	Severity       string `json:"severity"` // "critical" or "warning"
+	FilePath       string `json:"file_path,omitempty"`
+	Line           int    `json:"line,omitempty"`     // Set for findings in code rather than a tool description
+	Language       string `json:"language,omitempty"` // Language of the matched instruction, for injection rules
+	FileContext
 }
 
 // ToolDefinition represents an extracted tool
@@ -32,22 +52,33 @@ type ToolDefinition struct {
 	Description string                 `json:"description"`
 	Parameters  map[string]interface{} `json:"parameters,omitempty"`
 	Hash        string                 `json:"hash"` // SHA256 of normalized content
+	Outputs     []ToolOutput           `json:"outputs,omitempty"`
+	OutputHash  string                 `json:"output_hash,omitempty"` // SHA256 of Outputs, tracked separately from Hash
+	SourceFile  string                 `json:"source_file,omitempty"`
+	FileContext
 }
 
 // CheckIntegrity scans a repository for tool definitions and poisoning indicators
-func CheckIntegrity(repoPath string) (*IntegrityResult, []*ToolDefinition, error) {
+func CheckIntegrity(rc *RepoContext) (*IntegrityResult, []*ToolDefinition, error) {
 	result := &IntegrityResult{
 		Status:               "pass",
 		HiddenInstructions:   make([]IntegrityFinding, 0),
 		SuspiciousParameters: make([]IntegrityFinding, 0),
 		LongDescriptions:     make([]IntegrityFinding, 0),
 		CrossToolReferences:  make([]IntegrityFinding, 0),
+
+		ServerRequests:         make([]ServerRequest, 0),
+		SamplingInjections:     make([]IntegrityFinding, 0),
+		CredentialElicitations: make([]IntegrityFinding, 0),
+		OutputInjections:       make([]IntegrityFinding, 0),
+		MutableTools:           make([]MutableTool, 0),
+		MutationRisk:           MutationRiskStatic,
 	}
 
 	tools := make([]*ToolDefinition, 0)
 
 	// Walk the repository
-	err := filepath.Walk(repoPath, func(path string, info os.FileInfo, err error) error {
+	err := filepath.Walk(rc.Path, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // Skip files we can't read
 		}
@@ -74,9 +105,22 @@ func CheckIntegrity(repoPath string) (*IntegrityResult, []*ToolDefinition, error
 		}
 
 		// Extract tools from this file
+		relPath := rc.RelPath(path)
+		fc := rc.Context(relPath)
 		fileTools := extractTools(string(content), ext)
+		findToolOutputs(string(content), ext, fileTools)
+		for _, tool := range fileTools {
+			tool.SourceFile = relPath
+			tool.FileContext = fc
+		}
 		tools = append(tools, fileTools...)
 
+		// Prompts sent through sampling and credentials requested through elicitation
+		scanServerRequests(string(content), relPath, fc, result)
+
+		// Descriptions, schemas and tool lists read from the network, env, files or the clock
+		scanMutableDefinitions(string(content), relPath, fc, result)
+
 		return nil
 	})
 
@@ -89,18 +133,37 @@ func CheckIntegrity(repoPath string) (*IntegrityResult, []*ToolDefinition, error
 	// Scan each tool for poisoning indicators
 	for _, tool := range tools {
 		scanToolForPoison(tool, result)
+		scanToolOutputs(tool, result)
 	}
 
-	// Determine overall status
-	if len(result.HiddenInstructions) > 0 {
+	for _, mt := range result.MutableTools {
+		if rc.Counts(mt.FileContext) {
+			result.MutationRisk = MutationRiskRuntime
+			break
+		}
+	}
+
+	// Determine overall status from findings in trusted locations
+	if countedFindings(rc, result.HiddenInstructions)+countedFindings(rc, result.SamplingInjections)+countedFindings(rc, result.CredentialElicitations)+countedFindings(rc, result.OutputInjections) > 0 {
 		result.Status = "critical"
-	} else if len(result.SuspiciousParameters) > 0 || len(result.LongDescriptions) > 0 || len(result.CrossToolReferences) > 0 {
+	} else if countedFindings(rc, result.SuspiciousParameters)+countedFindings(rc, result.LongDescriptions)+countedFindings(rc, result.CrossToolReferences) > 0 || result.MutationRisk == MutationRiskRuntime {
 		result.Status = "warning"
 	}
 
 	return result, tools, nil
 }
 
+// countedFindings returns how many findings are confident enough to affect status
+func countedFindings(rc *RepoContext, findings []IntegrityFinding) int {
+	count := 0
+	for _, finding := range findings {
+		if rc.Counts(finding.FileContext) {
+			count++
+		}
+	}
+	return count
+}
+
 // extractTools extracts tool definitions from source code
 func extractTools(content, fileExt string) []*ToolDefinition {
 	tools := make([]*ToolDefinition, 0)
@@ -127,8 +190,8 @@ func extractTypeScriptTools(content string) []*ToolDefinition {
 		if len(match) >= 3 {
 			tools = append(tools, &ToolDefinition{
 				Name:        match[1],
-				Description: truncate(match[2], 2000),
-				Hash:        computeHash(match[1], match[2]),
+				Description: truncate(sourceDescription(match[2]), maxDescriptionLen),
+				Hash:        computeHash(match[1], sourceDescription(match[2])),
 			})
 		}
 	}
@@ -139,8 +202,8 @@ func extractTypeScriptTools(content string) []*ToolDefinition {
 		if len(match) >= 3 {
 			tools = append(tools, &ToolDefinition{
 				Name:        match[1],
-				Description: truncate(match[2], 2000),
-				Hash:        computeHash(match[1], match[2]),
+				Description: truncate(sourceDescription(match[2]), maxDescriptionLen),
+				Hash:        computeHash(match[1], sourceDescription(match[2])),
 			})
 		}
 	}
@@ -151,8 +214,8 @@ func extractTypeScriptTools(content string) []*ToolDefinition {
 		if len(match) >= 3 {
 			tools = append(tools, &ToolDefinition{
 				Name:        match[1],
-				Description: truncate(match[2], 2000),
-				Hash:        computeHash(match[1], match[2]),
+				Description: truncate(sourceDescription(match[2]), maxDescriptionLen),
+				Hash:        computeHash(match[1], sourceDescription(match[2])),
 			})
 		}
 	}
@@ -170,8 +233,8 @@ func extractPythonTools(content string) []*ToolDefinition {
 		if len(match) >= 3 {
 			tools = append(tools, &ToolDefinition{
 				Name:        match[1],
-				Description: truncate(match[2], 2000),
-				Hash:        computeHash(match[1], match[2]),
+				Description: truncate(sourceDescription(match[2]), maxDescriptionLen),
+				Hash:        computeHash(match[1], sourceDescription(match[2])),
 			})
 		}
 	}
@@ -182,8 +245,8 @@ func extractPythonTools(content string) []*ToolDefinition {
 		if len(match) >= 3 {
 			tools = append(tools, &ToolDefinition{
 				Name:        match[1],
-				Description: truncate(match[2], 2000),
-				Hash:        computeHash(match[1], match[2]),
+				Description: truncate(sourceDescription(match[2]), maxDescriptionLen),
+				Hash:        computeHash(match[1], sourceDescription(match[2])),
 			})
 		}
 	}
@@ -195,59 +258,17 @@ func extractPythonTools(content string) []*ToolDefinition {
 func scanToolForPoison(tool *ToolDefinition, result *IntegrityResult) {
 	desc := tool.Description
 
-	// CRITICAL: Hidden instruction tags
-	if hiddenInstructionPattern.MatchString(desc) {
-		matches := hiddenInstructionPattern.FindAllString(desc, -1)
-		for _, match := range matches {
-			result.HiddenInstructions = append(result.HiddenInstructions, IntegrityFinding{
-				ToolName:       tool.Name,
-				PatternMatched: "hidden_instruction_tag",
-				Snippet:        truncate(match, 200),
-				Severity:       "critical",
-			})
-		}
-	}
-
-	// CRITICAL: File exfiltration
-	for _, pattern := range fileExfiltrationPatterns {
-		if pattern.MatchString(desc) {
-			match := pattern.FindString(desc)
-			result.HiddenInstructions = append(result.HiddenInstructions, IntegrityFinding{
-				ToolName:       tool.Name,
-				PatternMatched: "file_exfiltration",
-				Snippet:        truncate(match, 200),
-				Severity:       "critical",
-			})
-			break
-		}
-	}
-
-	// CRITICAL: Data exfiltration
-	for _, pattern := range dataExfiltrationPatterns {
-		if pattern.MatchString(desc) {
-			match := pattern.FindString(desc)
-			result.HiddenInstructions = append(result.HiddenInstructions, IntegrityFinding{
-				ToolName:       tool.Name,
-				PatternMatched: "data_exfiltration",
-				Snippet:        truncate(match, 200),
-				Severity:       "critical",
-			})
-			break
-		}
-	}
-
-	// CRITICAL: Concealment instructions
-	for _, pattern := range concealmentPatterns {
-		if pattern.MatchString(desc) {
-			match := pattern.FindString(desc)
-			result.HiddenInstructions = append(result.HiddenInstructions, IntegrityFinding{
-				ToolName:       tool.Name,
-				PatternMatched: "concealment",
-				Snippet:        truncate(match, 200),
-				Severity:       "critical",
-			})
-			break
-		}
+	// CRITICAL: Hidden instructions, exfiltration and concealment
+	for _, match := range scanForInjection(desc) {
+		result.HiddenInstructions = append(result.HiddenInstructions, IntegrityFinding{
+			ToolName:       tool.Name,
+			PatternMatched: match.Pattern,
+			Snippet:        match.Snippet,
+			Severity:       "critical",
+			FilePath:       tool.SourceFile,
+			Language:       match.Language,
+			FileContext:    tool.FileContext,
+		})
 	}
 
 	// WARNING: Long descriptions
@@ -257,6 +278,8 @@ func scanToolForPoison(tool *ToolDefinition, result *IntegrityResult) {
 			PatternMatched: "long_description",
 			Snippet:        fmt.Sprintf("Description length: %d characters", len(desc)),
 			Severity:       "warning",
+			FilePath:       tool.SourceFile,
+			FileContext:    tool.FileContext,
 		})
 	}
 
@@ -269,6 +292,8 @@ func scanToolForPoison(tool *ToolDefinition, result *IntegrityResult) {
 					PatternMatched: "suspicious_parameter",
 					Snippet:        fmt.Sprintf("Parameter: %s", paramName),
 					Severity:       "warning",
+					FilePath:       tool.SourceFile,
+					FileContext:    tool.FileContext,
 				})
 			}
 		}
@@ -282,10 +307,75 @@ func scanToolForPoison(tool *ToolDefinition, result *IntegrityResult) {
 			PatternMatched: "cross_tool_reference",
 			Snippet:        truncate(match, 200),
 			Severity:       "warning",
+			FilePath:       tool.SourceFile,
+			FileContext:    tool.FileContext,
 		})
 	}
 }
 
+// injectionMatch is a prompt injection indicator found in text
+type injectionMatch struct {
+	Pattern  string // "hidden_instruction_tag", "file_exfiltration", "data_exfiltration", "concealment"
+	Snippet  string
+	Language string // Language the matched instruction is written in
+}
+
+// scanForInjection runs the critical injection rules over text an LLM will read
+// Every hidden instruction tag is reported, and the first match of each other rule; English rules
+// run on the text as written and normalized, localized rules on each sentence in their language
+func scanForInjection(text string) []injectionMatch {
+	matches := make([]injectionMatch, 0)
+	normalized := normalizeText(text)
+	language := detectLanguage(normalized)
+
+	tags := hiddenInstructionPattern.FindAllString(text, -1)
+	if len(tags) == 0 {
+		tags = hiddenInstructionPattern.FindAllString(normalized, -1)
+	}
+	for _, match := range tags {
+		matches = append(matches, injectionMatch{Pattern: "hidden_instruction_tag", Snippet: truncate(match, 200), Language: language})
+	}
+
+	rules := []struct {
+		name     string
+		patterns []*regexp.Regexp
+	}{
+		{"file_exfiltration", fileExfiltrationPatterns},
+		{"data_exfiltration", dataExfiltrationPatterns},
+		{"concealment", concealmentPatterns},
+	}
+	segments := segmentLanguages(normalized)
+	for _, rule := range rules {
+		if match, ok := firstInjectionMatch(rule.name, rule.patterns, "en", text, normalized); ok {
+			matches = append(matches, match)
+			continue
+		}
+		for _, lang := range localizedLanguages {
+			if len(segments[lang]) == 0 {
+				continue
+			}
+			if match, ok := firstInjectionMatch(rule.name, localizedRules[lang].rules(rule.name), lang, segments[lang]...); ok {
+				matches = append(matches, match)
+				break
+			}
+		}
+	}
+
+	return matches
+}
+
+// firstInjectionMatch returns the first match of a rule's patterns in any of the texts
+func firstInjectionMatch(name string, patterns []*regexp.Regexp, language string, texts ...string) (injectionMatch, bool) {
+	for _, pattern := range patterns {
+		for _, text := range texts {
+			if match := pattern.FindString(text); match != "" {
+				return injectionMatch{Pattern: name, Snippet: truncate(match, 200), Language: language}, true
+			}
+		}
+	}
+	return injectionMatch{}, false
+}
+
 // computeHash computes SHA256 hash of tool name + description
 func computeHash(name, description string) string {
 	content := name + ":" + description
@@ -293,6 +383,53 @@ func computeHash(name, description string) string {
 	return fmt.Sprintf("%x", hash)
 }
 
+// literalEscapes expands the escapes common to JS and Python string literals
+var literalEscapes = strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\'`, `'`, "\\`", "`")
+
+// sourceDescription turns a description literal as written in source into the text a client receives
+func sourceDescription(literal string) string {
+	return normalizeDescription(literalEscapes.Replace(literal))
+}
+
+// normalizeDescription trims and dedents a description the way Python's inspect.cleandoc does, so a
+// docstring hashes the same whether or not the SDK cleaned it before listing the tool
+func normalizeDescription(desc string) string {
+	desc = strings.ReplaceAll(desc, "\r\n", "\n")
+	lines := strings.Split(strings.ReplaceAll(desc, "\t", "        "), "\n")
+
+	// Common indentation of every line after the first that isn't blank
+	margin := -1
+	for _, line := range lines[1:] {
+		content := strings.TrimLeft(line, " ")
+		if content == "" {
+			continue
+		}
+		if indent := len(line) - len(content); margin < 0 || indent < margin {
+			margin = indent
+		}
+	}
+
+	lines[0] = strings.TrimLeft(lines[0], " ")
+	for i := 1; i < len(lines) && margin > 0; i++ {
+		if len(lines[i]) >= margin {
+			lines[i] = lines[i][margin:]
+		} else {
+			lines[i] = ""
+		}
+	}
+
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// maxDescriptionLen is how much of a tool description is stored
+const maxDescriptionLen = 2000
+
 // truncate truncates a string to maxLen characters
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -0,0 +1,129 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// LanguageUndetermined is reported when text has too few letters or stopwords to tell
+const LanguageUndetermined = "und"
+
+// segmentPattern splits text into sentences, so a payload appended in another language
+// is detected on its own; dots inside paths and URLs don't end a sentence
+var segmentPattern = regexp.MustCompile(`[.!?;]+(?:\s|$)|[\n。！？；]+`)
+
+// latinStopwords are common function words of the Latin-script languages with rule sets,
+// in normalized form
+var latinStopwords = map[string][]string{
+	"en": {"the", "and", "to", "of", "is", "this", "that", "for", "with", "you", "it", "not", "be", "are", "on", "from", "user", "do", "before", "any"},
+	"es": {"el", "los", "las", "que", "y", "en", "una", "es", "por", "para", "con", "no", "al", "del", "se", "lo", "le", "su", "usuario", "este", "esta", "antes"},
+	"fr": {"le", "les", "des", "et", "une", "est", "pour", "avec", "ne", "pas", "vous", "il", "ce", "cette", "du", "au", "qui", "utilisateur", "avant"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "mit", "fur", "den", "dem", "ein", "eine", "zu", "sie", "auf", "von", "dies", "diese", "benutzer", "nutzer", "vor"},
+	"pt": {"os", "as", "que", "e", "em", "um", "uma", "nao", "para", "com", "do", "da", "por", "usuario", "isso", "este", "esta", "voce", "antes"},
+	"it": {"il", "gli", "di", "che", "un", "una", "non", "per", "con", "del", "della", "questo", "questa", "utente", "sono", "prima"},
+}
+
+// normalizeText folds case, maps full-width and other compatibility characters to their plain
+// forms and strips diacritics from Latin letters, so rules match however the text is written
+func normalizeText(text string) string {
+	folded := cases.Fold().String(norm.NFKC.String(text))
+
+	var b strings.Builder
+	var prev rune
+	for _, r := range norm.NFD.String(folded) {
+		// Only Latin diacritics; kana voicing marks and Cyrillic й carry meaning
+		if unicode.Is(unicode.Mn, r) && unicode.Is(unicode.Latin, prev) {
+			continue
+		}
+		b.WriteRune(r)
+		prev = r
+	}
+	return norm.NFC.String(b.String())
+}
+
+// detectLanguage returns the ISO 639-1 code of the language normalized text is written in,
+// from its script, or from stopwords for Latin-script text
+func detectLanguage(normalized string) string {
+	var latin, han, kana, hangul, cyrillic, arabic int
+	for _, r := range normalized {
+		switch {
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Arabic, r):
+			arabic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	// CJK characters carry a word each, so they count for more than letters
+	cjk := "zh"
+	if kana > 0 {
+		cjk = "ja"
+	}
+	scripts := []struct {
+		language string
+		count    int
+	}{
+		{cjk, 3 * (han + kana)},
+		{"ko", 2 * hangul},
+		{"ru", cyrillic},
+		{"ar", arabic},
+	}
+	best := scripts[0]
+	for _, s := range scripts[1:] {
+		if s.count > best.count {
+			best = s
+		}
+	}
+	if best.count > 0 && best.count >= latin {
+		return best.language
+	}
+	if latin == 0 {
+		return LanguageUndetermined
+	}
+
+	// Latin script: the language with the most stopwords
+	words := strings.FieldsFunc(normalized, func(r rune) bool { return !unicode.IsLetter(r) })
+	language, hits := LanguageUndetermined, 0
+	for _, lang := range []string{"en", "es", "fr", "de", "pt", "it"} {
+		count := 0
+		stopwords := latinStopwords[lang]
+		for _, word := range words {
+			for _, stopword := range stopwords {
+				if word == stopword {
+					count++
+					break
+				}
+			}
+		}
+		if count > hits {
+			language, hits = lang, count
+		}
+	}
+	return language
+}
+
+// segmentLanguages returns each sentence of normalized text with its detected language
+func segmentLanguages(normalized string) map[string][]string {
+	segments := make(map[string][]string)
+	for _, segment := range segmentPattern.Split(normalized, -1) {
+		segment = strings.TrimSpace(segment)
+		if segment == "" {
+			continue
+		}
+		language := detectLanguage(segment)
+		segments[language] = append(segments[language], segment)
+	}
+	return segments
+}
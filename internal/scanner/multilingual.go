@@ -0,0 +1,188 @@
+package scanner
+
+import "regexp"
+
+// localizedRuleSet is one language's version of the critical injection rules
+type localizedRuleSet struct {
+	fileExfiltration []*regexp.Regexp
+	dataExfiltration []*regexp.Regexp
+	concealment      []*regexp.Regexp
+}
+
+// localizedLanguages are the languages with rule sets, in the order they're tried
+var localizedLanguages = []string{"es", "fr", "de", "pt", "it", "ru", "zh", "ja", "ko"}
+
+// localizedRules are the injection rules for languages other than English, written against
+// normalized text: case-folded, full-width characters mapped, Latin diacritics stripped
+// Like the English rules, each ties a sensitive noun or adverb to an action, so a tool that merely
+// mentions credentials or a private key isn't rated critical
+var localizedRules = map[string]localizedRuleSet{
+	"es": {
+		fileExfiltration: []*regexp.Regexp{
+			regexp.MustCompile(`(lee|leer|abre|abrir|obten|obtener).*~/`),
+			regexp.MustCompile(`contenidos? de.*\.env`),
+			regexp.MustCompile(`incluy\w*.*credenciales`),
+			regexp.MustCompile(`(lee|leer|incluy\w*|adjunt\w*|copia|copiar|obten|obtener).*(clave|llave) privada`),
+		},
+		dataExfiltration: []*regexp.Regexp{
+			regexp.MustCompile(`(envia|enviar|manda|mandar|reenvia|reenviar|sube|subir|transmite|transmitir).*http`),
+		},
+		concealment: []*regexp.Regexp{
+			regexp.MustCompile(`no (se lo |le |les )?(digas|diga|menciones|mencione|informes|informe|muestres|muestre|reveles|revele|cuentes|cuente)`),
+			regexp.MustCompile(`manten\w*.*(en secreto|oculto|confidencial)`),
+			regexp.MustCompile(`el usuario no (debe|deberia|puede) (saber|ver|enterarse)`),
+			regexp.MustCompile(`oculta\w* (esto )?(al|del) usuario`),
+			regexp.MustCompile(`sin (avisar|informar|decir\w*) al usuario`),
+		},
+	},
+	"fr": {
+		fileExfiltration: []*regexp.Regexp{
+			regexp.MustCompile(`(lis|lisez|lire|ouvre|ouvrez|ouvrir).*~/`),
+			regexp.MustCompile(`contenu.*\.env`),
+			regexp.MustCompile(`inclu\w*.*identifiants`),
+			regexp.MustCompile(`(lis|lisez|lire|inclu\w*|joins|joignez|joindre|copie|copiez|copier).*cle privee`),
+		},
+		dataExfiltration: []*regexp.Regexp{
+			regexp.MustCompile(`(envoie|envoyez|envoyer|transmets|transmettez|transmettre|televerse|televersez).*http`),
+		},
+		concealment: []*regexp.Regexp{
+			regexp.MustCompile(`ne (le |lui |leur |la )*(dis|dites|mentionne|mentionnez|revele|revelez|montre|montrez|informe|informez) (rien|pas)`),
+			regexp.MustCompile(`(garde|gardez).*(secret|cache|confidentiel)`),
+			regexp.MustCompile(`l'utilisateur ne (doit|devrait) pas (le )?(savoir|voir)`),
+			regexp.MustCompile(`cache[rz]? (ceci|cela|ca) (a|de) l'utilisateur`),
+			regexp.MustCompile(`sans (le dire a|en informer|prevenir) l'utilisateur`),
+		},
+	},
+	"de": {
+		fileExfiltration: []*regexp.Regexp{
+			regexp.MustCompile(`(lies|lese|lesen|offne|offnen).*~/`),
+			regexp.MustCompile(`inhalt.*\.env`),
+			regexp.MustCompile(`(fuge|fugen sie|hange|hangen sie|lies|lese|lesen|gib|geben sie|kopiere|kopieren sie) .*(zugangsdaten|privaten? schlussel)`),
+			regexp.MustCompile(`(zugangsdaten|privaten? schlussel).*(einfugen|anhangen|auslesen|mitsenden|hinzu)`),
+		},
+		dataExfiltration: []*regexp.Regexp{
+			regexp.MustCompile(`(sende|senden|schicke|schicken|ubertrage|ubertragen|lade).*http`),
+		},
+		concealment: []*regexp.Regexp{
+			regexp.MustCompile(`(sag|sage|sagen sie|erwahne|erwahnen sie|zeige|zeigen sie|verrate|verraten sie) (dem|den) (benutzer|nutzer)\w* (nichts|nicht)`),
+			regexp.MustCompile(`nicht (dem|den) (benutzer|nutzer)\w* (sagen|mitteilen|zeigen|erwahnen)`),
+			regexp.MustCompile(`(halte|halten sie).*(geheim|verborgen|vertraulich)`),
+			regexp.MustCompile(`(benutzer|nutzer) (darf|soll|sollte) (das |dies |es |davon )?nicht(s)? (wissen|sehen|erfahren)`),
+			regexp.MustCompile(`ohne (den|dem) (benutzer|nutzer)\w* (zu informieren|zu sagen|bescheid)`),
+		},
+	},
+	"pt": {
+		fileExfiltration: []*regexp.Regexp{
+			regexp.MustCompile(`(leia|ler|abra|abrir|obtenha).*~/`),
+			regexp.MustCompile(`conteudo.*\.env`),
+			regexp.MustCompile(`inclua.*credenciais`),
+			regexp.MustCompile(`(leia|ler|inclua|incluir|anexe|anexar|copie|copiar|obtenha).*chave privada`),
+		},
+		dataExfiltration: []*regexp.Regexp{
+			regexp.MustCompile(`(envie|enviar|mande|mandar|encaminhe|encaminhar|transmita|carregue).*http`),
+		},
+		concealment: []*regexp.Regexp{
+			regexp.MustCompile(`nao (lhe )?(diga|conte|mencione|informe|mostre|revele)`),
+			regexp.MustCompile(`mantenha.*(em segredo|oculto|confidencial)`),
+			regexp.MustCompile(`o usuario nao (deve|pode|precisa) (saber|ver)`),
+			regexp.MustCompile(`esconda (isso|isto) do usuario`),
+			regexp.MustCompile(`sem (avisar|informar|contar a?o?) (ao |o )?usuario`),
+		},
+	},
+	"it": {
+		fileExfiltration: []*regexp.Regexp{
+			regexp.MustCompile(`(leggi|leggere|apri|aprire).*~/`),
+			regexp.MustCompile(`contenuto.*\.env`),
+			regexp.MustCompile(`includi.*credenziali`),
+			regexp.MustCompile(`(leggi|leggere|includi|includere|allega|allegare|copia|copiare|ottieni).*chiave privata`),
+		},
+		dataExfiltration: []*regexp.Regexp{
+			regexp.MustCompile(`(invia|inviare|manda|mandare|inoltra|inoltrare|carica|caricare).*http`),
+		},
+		concealment: []*regexp.Regexp{
+			regexp.MustCompile(`non (lo )?(dire|dirlo|menzionare|informare|mostrare|rivelare)`),
+			regexp.MustCompile(`mantieni.*(segreto|nascosto|riservato)`),
+			regexp.MustCompile(`l'utente non (deve|dovrebbe) (saperlo|sapere|vedere)`),
+			regexp.MustCompile(`nascondi (questo|cio) all'utente`),
+			regexp.MustCompile(`senza (avvisare|informare|dirlo a) l'utente`),
+		},
+	},
+	"ru": {
+		fileExfiltration: []*regexp.Regexp{
+			regexp.MustCompile(`(прочитай|прочти|прочитать|открой|открыть).*~/`),
+			regexp.MustCompile(`содержим\S*.*\.env`),
+			regexp.MustCompile(`(прочитай|прочти|включи|включите|добавь|добавьте|приложи|приложите|скопируй|скопируйте).*((учетн|учётн)\S* данн|(приватн|закрыт)\S* ключ)`),
+		},
+		dataExfiltration: []*regexp.Regexp{
+			regexp.MustCompile(`(отправь|отправьте|отправить|перешли|перешлите|передай|передайте|загрузи|загрузите).*http`),
+		},
+		concealment: []*regexp.Regexp{
+			regexp.MustCompile(`не (говори|говорите|сообщай|сообщайте|упоминай|упоминайте|показывай|показывайте|рассказывай|рассказывайте|раскрывай)`),
+			regexp.MustCompile(`(держи|держите|храни|храните).*(в секрете|в тайне)`),
+			regexp.MustCompile(`пользовател\S* не (должен|должна|нужно|следует) (знать|видеть)`),
+			regexp.MustCompile(`скр(ой|ойте|ыть) (это )?от пользовател`),
+			regexp.MustCompile(`без ведома пользовател`),
+		},
+	},
+	"zh": {
+		fileExfiltration: []*regexp.Regexp{
+			regexp.MustCompile(`(读取|讀取|读|讀|打开|打開).{0,20}~/`),
+			regexp.MustCompile(`\.env.{0,10}(内容|內容)`),
+			regexp.MustCompile(`(读取|讀取|包含|附上|附加|复制|複製|获取|獲取).{0,20}(私钥|私鑰|凭证|憑證|凭据|憑據)`),
+		},
+		dataExfiltration: []*regexp.Regexp{
+			regexp.MustCompile(`(发送|發送|上传|上傳|转发|轉發|传输|傳輸).{0,40}http`),
+		},
+		concealment: []*regexp.Regexp{
+			regexp.MustCompile(`不要(告诉|告訴|告知|提及|提到|透露|显示|顯示|让|讓)`),
+			regexp.MustCompile(`(对|對|向)(用户|用戶|使用者)(保密|隐瞒|隱瞞)`),
+			regexp.MustCompile(`(用户|用戶|使用者)不(应|應|应该|應該|能|可以|需要)(知道|看到)`),
+			regexp.MustCompile(`(悄悄|偷偷|秘密)地?.{0,10}(发送|發送|上传|上傳|转发|轉發|读取|讀取|执行|執行|修改|删除|刪除)`),
+		},
+	},
+	"ja": {
+		fileExfiltration: []*regexp.Regexp{
+			regexp.MustCompile(`(読み取|読んで|読み込|開いて).{0,20}~/`),
+			regexp.MustCompile(`\.env.{0,10}内容`),
+			regexp.MustCompile(`(秘密鍵|認証情報).{0,20}(読み取|読んで|読み込|含め|添付|コピー)`),
+		},
+		dataExfiltration: []*regexp.Regexp{
+			regexp.MustCompile(`(送信|アップロード|転送).{0,40}http`),
+			regexp.MustCompile(`http\S*.{0,20}(に|へ)(送信|アップロード|転送)`),
+		},
+		concealment: []*regexp.Regexp{
+			regexp.MustCompile(`(ユーザー|ユーザ|利用者)に(は)?(伝え|言わ|知らせ|見せ|教え)(ない|るな)`),
+			regexp.MustCompile(`(秘密|内緒)に(して|する)`),
+			regexp.MustCompile(`(黙って|こっそり).{0,20}(送信|アップロード|転送|読み取|読み込|実行|削除|変更)`),
+		},
+	},
+	"ko": {
+		fileExfiltration: []*regexp.Regexp{
+			regexp.MustCompile(`(읽어|읽고|열어).{0,20}~/`),
+			regexp.MustCompile(`\.env.{0,10}내용`),
+			regexp.MustCompile(`(개인 ?키|자격 ?증명).{0,20}(읽어|읽고|포함|첨부|복사)`),
+		},
+		dataExfiltration: []*regexp.Regexp{
+			regexp.MustCompile(`(보내|전송|업로드).{0,40}http`),
+			regexp.MustCompile(`http\S*.{0,20}(보내|전송|업로드)`),
+		},
+		concealment: []*regexp.Regexp{
+			regexp.MustCompile(`(사용자|유저)에게 (말하지|알리지|보여주지|언급하지) (마|말)`),
+			regexp.MustCompile(`(사용자|유저)에게(는)? 비밀로`),
+			regexp.MustCompile(`몰래.{0,20}(보내|전송|업로드|읽|실행|삭제|변경)`),
+		},
+	},
+}
+
+// rules returns the rule set's patterns for an injection rule name
+func (s localizedRuleSet) rules(name string) []*regexp.Regexp {
+	switch name {
+	case "file_exfiltration":
+		return s.fileExfiltration
+	case "data_exfiltration":
+		return s.dataExfiltration
+	case "concealment":
+		return s.concealment
+	}
+	return nil
+}
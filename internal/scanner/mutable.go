@@ -0,0 +1,333 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MutableTool is a tool whose description, schema or presence is decided at runtime,
+// so the stored definition hash says nothing about what clients are served
+type MutableTool struct {
+	ToolName string `json:"tool_name"` // "*" when the whole tool list is built at runtime
+	Field    string `json:"field"`     // "description", "schema" or "tool_list"
+	Source   string `json:"source"`    // "network", "env", "file" or "time"
+	Evidence
+}
+
+// Mutation risk levels stored per server
+const (
+	MutationRiskStatic  = "static"  // Every definition is fixed in the source
+	MutationRiskRuntime = "runtime" // Some definitions can change without a new release
+)
+
+// Patterns for runtime-mutable tool definitions
+var (
+	// Sources whose value can change between the scan and a client's tools/list
+	mutableSourcePatterns = []struct {
+		Source  string
+		Pattern *regexp.Regexp
+	}{
+		{"network", regexp.MustCompile(`\bfetch\s*\(|\baxios\b|\bgot\s*\(|\bhttps?\.get\s*\(|\brequests\.(?:get|post|request)\s*\(|\bhttpx\.|\burlopen\s*\(|\baiohttp\b|\bClientSession\s*\(`)},
+		{"env", regexp.MustCompile(`process\.env\b|import\.meta\.env\b|os\.environ\b|os\.getenv\s*\(|\bgetenv\s*\(`)},
+		{"file", regexp.MustCompile(`readFileSync\s*\(|\breadFile\s*\(|fs\.promises\b|\bopen\s*\(|\.read_text\s*\(|\.read_bytes\s*\(|json\.load\s*\(|yaml\.safe_load\s*\(`)},
+		{"time", regexp.MustCompile(`new Date\b|Date\.now\s*\(|datetime\.(?:now|today|utcnow)\s*\(|date\.today\s*\(|\btime\.time\s*\(|\.getHours\s*\(|\.getDay\s*\(|\.getDate\s*\(`)},
+	}
+
+	// Where a description or schema expression starts
+	descriptionExprPattern = regexp.MustCompile(`\bdescription\s*[:=]\s*|\.__doc__\s*=\s*`)
+	schemaExprPattern      = regexp.MustCompile(`\b(?:inputSchema|input_schema|parameters)\s*[:=]\s*`)
+
+	// server.tool("name", <description>, ...)
+	toolCallDescriptionPattern = regexp.MustCompile(`\.(?:tool|registerTool)\s*\(\s*["'\x60]([^"'\x60]+)["'\x60]\s*,\s*`)
+
+	// Tool names near a description expression
+	nearbyToolNamePattern = regexp.MustCompile(`(?:\.(?:tool|registerTool)\s*\(\s*|\bname\s*[:=]\s*)["'\x60]([^"'\x60]+)["'\x60]|\bdef\s+(\w+)\s*\(`)
+
+	// tools/list handlers
+	listToolsHandlerPattern = regexp.MustCompile(`setRequestHandler\s*\(\s*ListToolsRequestSchema\s*,|@\w+\.list_tools\s*\(\s*\)`)
+
+	// A registration guarded by a date or time condition
+	timeConditionPattern = regexp.MustCompile(`\bif\b[^\n]*(?:new Date\b|Date\.now\s*\(|datetime\.|date\.today\s*\(|\btime\.time\s*\(|\.getHours\s*\(|\.getDay\s*\(|\.getDate\s*\()`)
+
+	// A plain string literal with nothing interpolated
+	plainLiteralPattern = regexp.MustCompile("^(?:\"(?:[^\"\\\\\\n]|\\\\.)*\"|'(?:[^'\\\\\\n]|\\\\.)*'|`[^`$]*`|\"\"\"[^{]*?\"\"\")$")
+
+	// Function calls inside an expression
+	calledFunctionPattern = regexp.MustCompile(`\b([A-Za-z_]\w*)\s*\(`)
+
+	// A Python function definition, up to its opening paren
+	pythonDefPattern = regexp.MustCompile(`\bdef\s+\w+\s*\(`)
+)
+
+// scanMutableDefinitions records tools whose description or schema comes from the network,
+// env vars, file reads or the clock, and tool lists built from them
+func scanMutableDefinitions(content, relPath string, fc FileContext, result *IntegrityResult) {
+	seen := make(map[string]bool)
+	record := func(name, field, source string, offset int) {
+		key := name + "\x00" + field
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		result.MutableTools = append(result.MutableTools, MutableTool{
+			ToolName: name,
+			Field:    field,
+			Source:   source,
+			Evidence: evidenceAt(content, relPath, fc, offset),
+		})
+	}
+
+	// Second argument of server.tool("name", ...)
+	for _, m := range toolCallDescriptionPattern.FindAllStringSubmatchIndex(content, -1) {
+		expr := argumentExpr(content, m[1])
+		if strings.HasPrefix(expr, "{") {
+			continue // The schema or config object, checked below
+		}
+		if source := mutableSource(content, expr); source != "" {
+			record(content[m[2]:m[3]], "description", source, m[0])
+		}
+	}
+
+	// description: / description= / fn.__doc__ = next to a tool name; without one it's usually
+	// a tool result built from API data rather than a definition
+	for _, m := range descriptionExprPattern.FindAllStringIndex(content, -1) {
+		name := nearbyToolName(content, m[0])
+		if name == "" {
+			continue
+		}
+		if source := mutableSource(content, argumentExpr(content, m[1])); source != "" {
+			record(name, "description", source, m[0])
+		}
+	}
+
+	for _, m := range schemaExprPattern.FindAllStringIndex(content, -1) {
+		name := nearbyToolName(content, m[0])
+		expr := argumentExpr(content, m[1])
+		if name == "" || strings.HasPrefix(expr, "{") || strings.HasPrefix(expr, "z.") {
+			continue // Inline schemas are fixed unless they interpolate a source, which is too rare to chase
+		}
+		if source := mutableSource(content, expr); source != "" {
+			record(name, "schema", source, m[0])
+		}
+	}
+
+	// Registrations under a date condition appear or change depending on when clients connect
+	for _, m := range toolCallDescriptionPattern.FindAllStringSubmatchIndex(content, -1) {
+		if insideTimeCondition(content, m[0]) {
+			record(content[m[2]:m[3]], "tool_list", "time", m[0])
+		}
+	}
+
+	// tools/list handlers that build the list from a mutable source
+	for _, m := range listToolsHandlerPattern.FindAllStringIndex(content, -1) {
+		var body string
+		if content[m[0]] == '@' {
+			// The decorated function follows
+			if def := pythonDefPattern.FindStringIndex(content[m[1]:]); def != nil {
+				body = functionBody(content, m[1]+def[1])
+			}
+		} else {
+			body = untilClose(content[m[1]:min(len(content), m[1]+maxRequestArgs)])
+		}
+		if source := classifySource(body); source != "" {
+			record("*", "tool_list", source, m[0])
+		}
+	}
+}
+
+// insideTimeCondition reports whether offset is inside an if block testing the date or time
+func insideTimeCondition(content string, offset int) bool {
+	before := content[max(0, offset-400):offset]
+	locs := timeConditionPattern.FindAllStringIndex(before, -1)
+	if len(locs) == 0 {
+		return false
+	}
+	block := before[locs[len(locs)-1][0]:]
+
+	// Braced block still open, or an indented Python block
+	if strings.Contains(block, "{") {
+		return strings.Count(block, "{") > strings.Count(block, "}")
+	}
+	lines := strings.Split(block, "\n")
+	if len(lines) < 2 {
+		return false
+	}
+	ifIndent := len(before[:locs[len(locs)-1][0]]) - strings.LastIndexByte(before[:locs[len(locs)-1][0]], '\n') - 1
+	lineStart := strings.LastIndexByte(content[:offset], '\n') + 1
+	indent := len(content[lineStart:]) - len(strings.TrimLeft(content[lineStart:], " \t"))
+	for _, line := range lines[1 : len(lines)-1] {
+		if strings.TrimSpace(line) != "" && len(line)-len(strings.TrimLeft(line, " \t")) <= ifIndent {
+			return false // The block ended before offset
+		}
+	}
+	return indent > ifIndent
+}
+
+// mutableSource classifies a description or schema expression, following one assignment
+// or local function call; it returns "" for literals and values it can't resolve
+func mutableSource(content, expr string) string {
+	expr = strings.TrimSpace(expr)
+	if expr == "" || plainLiteralPattern.MatchString(expr) {
+		return ""
+	}
+	if source := classifySource(expr); source != "" {
+		return source
+	}
+
+	// A variable assigned in the same file
+	if ident := leadingIdentifier(expr); ident != "" {
+		assign := regexp.MustCompile(`(?m)(?:\b(?:const|let|var)\s+|^\s*)` + regexp.QuoteMeta(ident) + `\s*(?::[^=\n]+)?=[^=>]`)
+		if loc := assign.FindStringIndex(content); loc != nil {
+			rhs := argumentExpr(content, loc[1]-1)
+			if source := classifySource(rhs); source != "" {
+				return source
+			}
+			expr = rhs
+		}
+	}
+
+	// A function defined in the same file
+	for _, call := range calledFunctionPattern.FindAllStringSubmatch(expr, -1) {
+		def := regexp.MustCompile(`(?:\bfunction\s+|\bdef\s+|\b(?:const|let|var)\s+)` + regexp.QuoteMeta(call[1]) + `\b\s*(?:=\s*(?:async\s*)?)?\(`)
+		if loc := def.FindStringIndex(content); loc != nil {
+			if source := classifySource(functionBody(content, loc[1])); source != "" {
+				return source
+			}
+		}
+	}
+	return ""
+}
+
+// classifySource returns the first mutable source used in code, or ""
+func classifySource(code string) string {
+	for _, s := range mutableSourcePatterns {
+		if s.Pattern.MatchString(code) {
+			return s.Source
+		}
+	}
+	return ""
+}
+
+// argumentExpr returns the expression starting at offset, up to the next top-level comma,
+// semicolon, newline or closing bracket
+func argumentExpr(content string, offset int) string {
+	end := min(len(content), offset+maxRequestArgs)
+	depth := 0
+	var quote byte
+	for i := offset; i < end; i++ {
+		c := content[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			if depth == 0 {
+				return strings.TrimSpace(content[offset:i])
+			}
+			depth--
+		case depth == 0 && (c == ',' || c == ';' || c == '\n'):
+			return strings.TrimSpace(content[offset:i])
+		}
+	}
+	return strings.TrimSpace(content[offset:end])
+}
+
+// leadingIdentifier returns the variable an expression starts with, skipping await
+func leadingIdentifier(expr string) string {
+	expr = strings.TrimPrefix(expr, "await ")
+	end := 0
+	for end < len(expr) && (expr[end] == '_' || expr[end] == '$' ||
+		(expr[end] >= 'a' && expr[end] <= 'z') || (expr[end] >= 'A' && expr[end] <= 'Z') ||
+		(end > 0 && expr[end] >= '0' && expr[end] <= '9')) {
+		end++
+	}
+	return expr[:end]
+}
+
+// nearbyToolName returns the tool name closest to offset, or "" when there is none nearby
+func nearbyToolName(content string, offset int) string {
+	name, distance := "", -1
+	start := max(0, offset-600)
+	for _, m := range nearbyToolNamePattern.FindAllStringSubmatchIndex(content[start:offset], -1) {
+		name, distance = submatchName(content[start:offset], m), offset-(start+m[1])
+	}
+
+	// Python's @mcp.tool(description=...) and Tool(description=..., name=...) put the name after
+	after := content[offset:min(len(content), offset+300)]
+	if m := nearbyToolNamePattern.FindStringSubmatchIndex(after); m != nil && (distance < 0 || m[0] < distance) {
+		name = submatchName(after, m)
+	}
+	return name
+}
+
+// submatchName returns whichever name group of nearbyToolNamePattern matched
+func submatchName(s string, m []int) string {
+	if m[2] >= 0 {
+		return s[m[2]:m[3]]
+	}
+	return s[m[4]:m[5]]
+}
+
+// functionBody returns the body of a function whose parameter list opens just before offset:
+// a braced block, an arrow function's expression, or an indented Python block
+func functionBody(content string, offset int) string {
+	code := content[offset:min(len(content), offset+maxRequestArgs)]
+	params := untilClose(code)
+	rest := code[min(len(code), len(params)+1):]
+
+	trimmed := strings.TrimSpace(rest)
+	if strings.HasPrefix(trimmed, "=>") {
+		arrow := strings.TrimSpace(trimmed[2:])
+		if !strings.HasPrefix(arrow, "{") {
+			return argumentExpr(arrow, 0)
+		}
+	}
+
+	brace := strings.IndexByte(rest, '{')
+	colon := strings.Index(rest, ":\n")
+	switch {
+	case brace >= 0 && (colon < 0 || brace < colon):
+		return untilClose(rest[brace+1:])
+	case colon >= 0:
+		body := rest[colon+2:]
+		if next := pythonHandlerEndPattern.FindStringIndex(body); next != nil {
+			body = body[:next[0]]
+		}
+		return body
+	}
+	return rest
+}
+
+// untilClose returns code up to the first closing bracket without a matching opener
+func untilClose(code string) string {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			if depth == 0 {
+				return code[:i]
+			}
+			depth--
+		}
+	}
+	return code
+}
@@ -0,0 +1,139 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mcpsek/mcpsek/internal/classifier"
+	"github.com/mcpsek/mcpsek/internal/database"
+	"github.com/mcpsek/mcpsek/internal/diff"
+)
+
+// MutationDiff is the structured change between two versions of a tool
+type MutationDiff struct {
+	Description []diff.Segment     `json:"description"`
+	Parameters  []diff.ParamChange `json:"parameters"`
+	// Text the new version added, the only text the integrity rules are run over
+	AddedText string             `json:"added_text,omitempty"`
+	Findings  []IntegrityFinding `json:"findings"`
+}
+
+// DiffTool diffs two versions of a tool and runs the integrity rules over what was added
+// A nil description is a tool that didn't exist on that side
+func DiffTool(toolName string, oldDesc, newDesc *string, oldParams, newParams json.RawMessage) *MutationDiff {
+	d := &MutationDiff{
+		Description: diff.Words(derefStr(oldDesc), derefStr(newDesc)),
+		Parameters:  make([]diff.ParamChange, 0),
+		Findings:    make([]IntegrityFinding, 0),
+	}
+
+	// A schema that doesn't parse leaves the parameter diff empty
+	if changes, err := diff.Schema(oldParams, newParams); err == nil {
+		d.Parameters = changes
+	}
+
+	added := []string{diff.Inserted(d.Description)}
+	addedParams := make(map[string]interface{})
+	for _, change := range d.Parameters {
+		if change.Change == "added" {
+			addedParams[change.Name] = change.NewType
+		}
+		if text := diff.Inserted(change.Description); text != "" {
+			added = append(added, text)
+		}
+	}
+	d.AddedText = strings.TrimSpace(strings.Join(added, "\n"))
+
+	result := &IntegrityResult{}
+	scanToolForPoison(&ToolDefinition{Name: toolName, Description: d.AddedText, Parameters: addedParams}, result)
+	for _, findings := range [][]IntegrityFinding{
+		result.HiddenInstructions, result.SuspiciousParameters, result.LongDescriptions, result.CrossToolReferences,
+	} {
+		d.Findings = append(d.Findings, findings...)
+	}
+
+	return d
+}
+
+// DiffMutation diffs a stored mutation; output mutations diff the text the tool returns
+func DiffMutation(m *database.Mutation) *MutationDiff {
+	if m.Kind == "output" {
+		return DiffTool(m.ToolName, outputText(m.OldOutputs), outputText(m.NewOutputs), nil, nil)
+	}
+	return DiffTool(m.ToolName, m.OldDescription, m.NewDescription, m.OldParameters, m.NewParameters)
+}
+
+// diffMutation diffs a mutation with the scanner's classifier applied to the added text
+func (s *Scanner) diffMutation(m *database.Mutation) (*MutationDiff, json.RawMessage) {
+	d := DiffMutation(m)
+	d.AddInjectionScore(s.opts.Classifier, s.opts.ClassifierThreshold, m.ToolName)
+	data, _ := json.Marshal(d)
+	return d, data
+}
+
+// outputText joins the texts of stored tool outputs, one per line
+func outputText(raw json.RawMessage) *string {
+	var outputs []ToolOutput
+	if err := json.Unmarshal(raw, &outputs); err != nil || len(outputs) == 0 {
+		return nil
+	}
+	texts := make([]string, len(outputs))
+	for i, output := range outputs {
+		texts[i] = output.Text
+	}
+	return strPtr(strings.Join(texts, "\n"))
+}
+
+// AddInjectionScore runs the classifier over the added text, like AddInjectionScores does for descriptions
+func (d *MutationDiff) AddInjectionScore(model *classifier.Model, threshold float64, toolName string) {
+	if model == nil || d.AddedText == "" {
+		return
+	}
+	if threshold <= 0 {
+		threshold = DefaultClassifierThreshold
+	}
+
+	if probability := model.Probability(d.AddedText); probability >= threshold {
+		d.Findings = append(d.Findings, IntegrityFinding{
+			ToolName:       toolName,
+			PatternMatched: "classifier",
+			Snippet:        fmt.Sprintf("Injection probability %.2f: %s", probability, truncate(d.AddedText, 200)),
+			Severity:       "warning",
+		})
+	}
+}
+
+// Severity rates the change by the worst finding in the added text
+func (d *MutationDiff) Severity() (string, string) {
+	for _, severity := range []string{"critical", "warning"} {
+		for _, finding := range d.Findings {
+			if finding.Severity == severity {
+				return severity, fmt.Sprintf("Added text contains %s", strings.ReplaceAll(finding.PatternMatched, "_", " "))
+			}
+		}
+	}
+
+	var added int
+	for _, segment := range d.Description {
+		if segment.Op == diff.Insert {
+			added += len(segment.Text)
+		}
+	}
+	if added > 200 {
+		return "warning", fmt.Sprintf("%d characters added to the description", added)
+	}
+
+	if len(d.Parameters) > 0 {
+		return "info", "Parameters changed"
+	}
+	return "info", "Minor description change"
+}
+
+// derefStr returns the string s points to, or "" for nil
+func derefStr(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
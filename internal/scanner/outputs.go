@@ -0,0 +1,129 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ToolOutput is a string literal a tool handler returns, or an error message it raises
+type ToolOutput struct {
+	Text string `json:"text"`
+	Line int    `json:"line"`
+}
+
+// Patterns for tool handler output
+var (
+	// String literals returned as content, returned directly or used as error messages
+	toolOutputPattern = regexp.MustCompile(`(?:\btext\s*[:=]\s*|\breturn\s+|\braise\s+[\w.]+\(\s*|Error\s*\(\s*|\bmessage\s*[:=]\s*)[rRbBfFuU]{0,2}(` + stringLiteralExpr + `)`)
+
+	// Start of the next handler, which ends the current one (TypeScript/JavaScript)
+	tsHandlerEndPattern = regexp.MustCompile(`\.(?:tool|registerTool)\s*\(|\bcase\s+["'\x60]|\bdefault\s*:`)
+
+	// Start of the next top-level statement, which ends a Python function body
+	pythonHandlerEndPattern = regexp.MustCompile(`\n[^\s#]`)
+)
+
+// maxHandlerLength bounds how much code after a handler's start is treated as its body
+const maxHandlerLength = 10000
+
+// findToolOutputs attaches the string literals each tool's handler returns or raises
+func findToolOutputs(content, fileExt string, tools []*ToolDefinition) {
+	for _, tool := range tools {
+		start, end := handlerSpan(content, fileExt, tool.Name)
+		if start < 0 {
+			continue
+		}
+
+		outputs := make([]ToolOutput, 0)
+		for _, m := range toolOutputPattern.FindAllStringSubmatchIndex(content[start:end], -1) {
+			text := unquoteLiteral(content[start+m[2] : start+m[3]])
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			outputs = append(outputs, ToolOutput{
+				Text: truncate(text, 2000),
+				Line: strings.Count(content[:start+m[2]], "\n") + 1,
+			})
+		}
+		if len(outputs) > 0 {
+			tool.Outputs = outputs
+			tool.OutputHash = computeOutputHash(outputs)
+		}
+	}
+}
+
+// handlerSpan locates a tool's handler body by name, returning -1 when it can't be found
+func handlerSpan(content, fileExt, name string) (int, int) {
+	quoted := regexp.QuoteMeta(name)
+	var starts []*regexp.Regexp
+	var endPattern *regexp.Regexp
+
+	switch fileExt {
+	case ".ts", ".tsx", ".js", ".jsx", ".mjs":
+		starts = []*regexp.Regexp{
+			regexp.MustCompile(`\.(?:tool|registerTool)\s*\(\s*["'\x60]` + quoted + `["'\x60]`),
+			regexp.MustCompile(`\bcase\s+["'\x60]` + quoted + `["'\x60]\s*:`),
+			regexp.MustCompile(`===?\s*["'\x60]` + quoted + `["'\x60]`),
+		}
+		endPattern = tsHandlerEndPattern
+	case ".py":
+		starts = []*regexp.Regexp{
+			regexp.MustCompile(`(?m)^(?:async\s+)?def\s+` + quoted + `\s*\(`),
+			regexp.MustCompile(`==\s*["']` + quoted + `["']`),
+		}
+		endPattern = pythonHandlerEndPattern
+	default:
+		return -1, -1
+	}
+
+	for _, pattern := range starts {
+		loc := pattern.FindStringIndex(content)
+		if loc == nil {
+			continue
+		}
+		end := loc[1] + maxHandlerLength
+		if end > len(content) {
+			end = len(content)
+		}
+		if next := endPattern.FindStringIndex(content[loc[1]:end]); next != nil {
+			end = loc[1] + next[0]
+		}
+		return loc[0], end
+	}
+	return -1, -1
+}
+
+// emptyOutputHash is the output hash of a tool that returns no literal text
+var emptyOutputHash = computeOutputHash(nil)
+
+// computeOutputHash computes a SHA256 hash of a tool's outputs, independent of their order and lines
+func computeOutputHash(outputs []ToolOutput) string {
+	texts := make([]string, len(outputs))
+	for i, output := range outputs {
+		texts[i] = output.Text
+	}
+	sort.Strings(texts)
+	hash := sha256.Sum256([]byte(strings.Join(texts, "\x00")))
+	return fmt.Sprintf("%x", hash)
+}
+
+// scanToolOutputs runs the poisoning rules over the text a tool returns
+func scanToolOutputs(tool *ToolDefinition, result *IntegrityResult) {
+	for _, output := range tool.Outputs {
+		for _, match := range scanForInjection(output.Text) {
+			result.OutputInjections = append(result.OutputInjections, IntegrityFinding{
+				ToolName:       tool.Name,
+				PatternMatched: match.Pattern,
+				Snippet:        match.Snippet,
+				Severity:       "critical",
+				FilePath:       tool.SourceFile,
+				Line:           output.Line,
+				Language:       match.Language,
+				FileContext:    tool.FileContext,
+			})
+		}
+	}
+}
@@ -66,9 +66,8 @@ var (
 
 	// ====== AUTHENTICATION POSTURE PATTERNS ======
 
-	// OAuth 2.0 indicators (PASS)
+	// OAuth 2.0 implementation indicators (PASS); the bare word "oauth" is too weak to count
 	oauthPatterns = []*regexp.Regexp{
-		regexp.MustCompile(`(?i)\boauth\b`),
 		regexp.MustCompile(`(?i)authorization_code`),
 		regexp.MustCompile(`(?i)\bpkce\b`),
 		regexp.MustCompile(`(?i)token_endpoint`),
@@ -83,6 +82,10 @@ var (
 		regexp.MustCompile(`(?i)grant_type`),
 	}
 
+	// OAuth token refresh and scope indicators
+	oauthRefreshPattern = regexp.MustCompile(`(?i)refresh_token|refreshToken|grant_type\s*[:=]\s*["']refresh_token`)
+	oauthScopePattern   = regexp.MustCompile(`(?i)\bscopes?\s*[:=]|requiredScopes|scopes_supported|[?&]scope=|["']scope["']\s*:`)
+
 	// Static secrets indicators (WARNING)
 	staticSecretPatterns = []*regexp.Regexp{
 		regexp.MustCompile(`(?i)process\.env\.(API_KEY|TOKEN|SECRET|PAT|PERSONAL_ACCESS_TOKEN|ACCESS_TOKEN)`),
@@ -96,30 +99,48 @@ var (
 		regexp.MustCompile(`(?i)"personalAccessToken"`),
 	}
 
-	// Committed secrets patterns
-	awsKeyPattern     = regexp.MustCompile(`AKIA[0-9A-Z]{16}`)
-	githubPatPattern  = regexp.MustCompile(`ghp_[A-Za-z0-9_]{36}`)
-	githubPat2Pattern = regexp.MustCompile(`github_pat_[A-Za-z0-9_]{82}`)
-	privateKeyPattern = regexp.MustCompile(`-----BEGIN (RSA |EC |OPENSSH )?PRIVATE KEY-----`)
-	slackTokenPattern = regexp.MustCompile(`xox[bp]-[0-9]{11,12}-[0-9]{11,12}-[a-zA-Z0-9]{24}`)
-	genericKeyPattern = regexp.MustCompile(`["']sk-[a-zA-Z0-9]{20,}["']`)
+	// Committed secret formats live in secrets.go
 
 	// ====== ENDPOINT EXPOSURE PATTERNS ======
 
-	// stdio indicators (safest)
-	stdioPatterns = []*regexp.Regexp{
-		regexp.MustCompile(`(?i)StdioServerTransport`),
-		regexp.MustCompile(`(?i)stdio_server`),
-		regexp.MustCompile(`(?i)server\.stdio`),
-		regexp.MustCompile(`(?i)transport.*stdio`),
-		regexp.MustCompile(`(?i)"type"\s*:\s*"stdio"`),
+	// MCP SDK transport classes, checked in order of exposure (most exposed first
+	// wins the primary transport). Each entry recognises the server-side class
+	// of the TypeScript, Python and Go SDKs
+	transportPatterns = []struct {
+		Transport string
+		Patterns  []*regexp.Regexp
+	}{
+		{"streamable_http", []*regexp.Regexp{
+			regexp.MustCompile(`StreamableHTTPServerTransport`),
+			regexp.MustCompile(`StreamableHTTPSessionManager`),
+			regexp.MustCompile(`streamable_http_app\(`),
+			regexp.MustCompile(`(?i)transport\s*=\s*["'](?:streamable-http|streamable_http|http)["']`),
+			regexp.MustCompile(`NewStreamableHTTPServer|NewStreamableHTTPHandler`),
+		}},
+		{"sse", []*regexp.Regexp{
+			regexp.MustCompile(`SSEServerTransport`),
+			regexp.MustCompile(`SseServerTransport`),
+			regexp.MustCompile(`sse_app\(`),
+			regexp.MustCompile(`(?i)transport\s*=\s*["']sse["']`),
+			regexp.MustCompile(`NewSSEServer|NewSSEHandler`),
+		}},
+		{"websocket", []*regexp.Regexp{
+			regexp.MustCompile(`WebSocketServerTransport`),
+			regexp.MustCompile(`websocket_server\(`),
+			regexp.MustCompile(`(?i)transport\s*=\s*["']websocket["']`),
+		}},
+		{"stdio", []*regexp.Regexp{
+			regexp.MustCompile(`StdioServerTransport`),
+			regexp.MustCompile(`stdio_server\(`),
+			regexp.MustCompile(`(?i)transport\s*=\s*["']stdio["']`),
+			regexp.MustCompile(`ServeStdio|NewStdioServer|mcp\.StdioTransport`),
+			regexp.MustCompile(`\bmcp\.run\(\s*\)`), // FastMCP defaults to stdio
+		}},
 	}
 
-	// Network transport indicators (risk)
-	networkTransportPatterns = []*regexp.Regexp{
-		regexp.MustCompile(`(?i)SSEServerTransport`),
-		regexp.MustCompile(`(?i)sse_server`),
-		regexp.MustCompile(`(?i)server\.sse`),
+	// Plain HTTP servers; reported as transport "http" only when no SDK
+	// network transport class was found
+	httpServerPatterns = []*regexp.Regexp{
 		regexp.MustCompile(`(?i)createServer.*listen`),
 		regexp.MustCompile(`(?i)app\.listen\(`),
 		regexp.MustCompile(`(?i)\bexpress\(\)`),
@@ -130,10 +151,6 @@ var (
 		regexp.MustCompile(`(?i)\.listen\(PORT`),
 		regexp.MustCompile(`(?i)http\.createServer`),
 		regexp.MustCompile(`(?i)\bHono\b`),
-		regexp.MustCompile(`(?i)WebSocketServerTransport`),
-		regexp.MustCompile(`(?i)ws_server`),
-		regexp.MustCompile(`(?i)websocket`),
-		regexp.MustCompile(`(?i)wss://`),
 	}
 
 	// Bind address patterns
@@ -0,0 +1,198 @@
+package scanner
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Import extraction patterns used to build the reachability graph
+var (
+	jsImportPattern  = regexp.MustCompile(`(?m)(?:^|[;\s])(?:import|export)\s+(?:[\w*{}\s,$]+\s+from\s+)?["']([^"']+)["']`)
+	jsRequirePattern = regexp.MustCompile(`\b(?:require|import)\s*\(\s*["']([^"']+)["']\s*\)`)
+	pyImportPattern  = regexp.MustCompile(`(?m)^\s*import\s+([\w.]+(?:\s*,\s*[\w.]+)*)`)
+	pyFromPattern    = regexp.MustCompile(`(?m)^\s*from\s+(\.*[\w.]*)\s+import\s+\(?\s*([\w\s,*]+)`)
+)
+
+// ReachabilitySummary counts source files by reachability from the entrypoints
+type ReachabilitySummary struct {
+	Reachable   int `json:"reachable"`
+	Unreachable int `json:"unreachable"`
+}
+
+// buildReachability walks the import graph from the entrypoints and returns every reachable source file
+func buildReachability(repoPath string, entrypoints []string) map[string]bool {
+	reachable := make(map[string]bool)
+	queue := make([]string, 0, len(entrypoints))
+	for _, entry := range entrypoints {
+		if !reachable[entry] {
+			reachable[entry] = true
+			queue = append(queue, entry)
+		}
+	}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		content, err := os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(current)))
+		if err != nil {
+			continue
+		}
+
+		var imports []string
+		if isPythonSource(current) {
+			imports = resolvePythonImports(repoPath, current, string(content))
+		} else {
+			imports = resolveJSImports(repoPath, current, string(content))
+		}
+
+		for _, imported := range imports {
+			if !reachable[imported] {
+				reachable[imported] = true
+				queue = append(queue, imported)
+			}
+		}
+	}
+
+	return reachable
+}
+
+// isReachabilitySource reports whether a file participates in the import graph
+func isReachabilitySource(relPath string) bool {
+	switch path.Ext(relPath) {
+	case ".ts", ".tsx", ".mts", ".cts", ".js", ".jsx", ".mjs", ".cjs", ".py":
+		return true
+	}
+	return false
+}
+
+// isPythonSource reports whether a file is Python source
+func isPythonSource(relPath string) bool {
+	return path.Ext(relPath) == ".py"
+}
+
+// resolveJSImports returns the repository files imported by a JS/TS file
+func resolveJSImports(repoPath, fromFile, content string) []string {
+	specifiers := make([]string, 0)
+	for _, match := range jsImportPattern.FindAllStringSubmatch(content, -1) {
+		specifiers = append(specifiers, match[1])
+	}
+	for _, match := range jsRequirePattern.FindAllStringSubmatch(content, -1) {
+		specifiers = append(specifiers, match[1])
+	}
+
+	fromDir := path.Dir(fromFile)
+	resolved := make([]string, 0, len(specifiers))
+	for _, spec := range specifiers {
+		var candidates []string
+		switch {
+		case strings.HasPrefix(spec, "./") || strings.HasPrefix(spec, "../"):
+			candidates = []string{path.Join(fromDir, spec)}
+		case strings.HasPrefix(spec, "@/") || strings.HasPrefix(spec, "~/"):
+			// Common tsconfig path aliases for the source root
+			candidates = []string{path.Join("src", spec[2:]), spec[2:]}
+		case strings.HasPrefix(spec, "src/"):
+			// baseUrl-style imports
+			candidates = []string{spec}
+		default:
+			// Bare package specifiers live outside the repository
+			continue
+		}
+
+		for _, candidate := range candidates {
+			if strings.HasPrefix(candidate, "..") {
+				continue
+			}
+			if file := resolveJSFile(repoPath, candidate); file != "" {
+				resolved = append(resolved, file)
+				break
+			}
+		}
+	}
+
+	return resolved
+}
+
+// resolvePythonImports returns the repository files imported by a Python file
+func resolvePythonImports(repoPath, fromFile, content string) []string {
+	modules := make([]string, 0)
+	for _, match := range pyImportPattern.FindAllStringSubmatch(content, -1) {
+		for _, name := range strings.Split(match[1], ",") {
+			modules = append(modules, strings.TrimSpace(name))
+		}
+	}
+
+	fromDir := path.Dir(fromFile)
+	relative := make([]string, 0)
+	for _, match := range pyFromPattern.FindAllStringSubmatch(content, -1) {
+		module := match[1]
+		names := strings.Split(match[2], ",")
+
+		if strings.HasPrefix(module, ".") {
+			// Relative import: each leading dot beyond the first climbs one package
+			dots := len(module) - len(strings.TrimLeft(module, "."))
+			base := fromDir
+			for i := 1; i < dots; i++ {
+				base = path.Dir(base)
+			}
+			rest := strings.ReplaceAll(strings.TrimLeft(module, "."), ".", "/")
+			target := path.Join(base, rest)
+			relative = append(relative, target)
+			for _, name := range names {
+				if name = strings.TrimSpace(name); name != "" && name != "*" {
+					relative = append(relative, path.Join(target, name))
+				}
+			}
+			continue
+		}
+
+		modules = append(modules, module)
+		for _, name := range names {
+			if name = strings.TrimSpace(name); name != "" && name != "*" {
+				modules = append(modules, module+"."+name)
+			}
+		}
+	}
+
+	// Absolute imports resolve against the importing file's ancestors and the usual source roots
+	roots := []string{".", "src"}
+	for dir := fromDir; dir != "." && dir != "/"; dir = path.Dir(dir) {
+		roots = append(roots, dir)
+	}
+
+	resolved := make([]string, 0)
+	for _, module := range modules {
+		modPath := strings.ReplaceAll(module, ".", "/")
+		for _, root := range roots {
+			if file := resolvePythonFile(repoPath, path.Join(root, modPath)); file != "" {
+				resolved = append(resolved, file)
+				break
+			}
+		}
+	}
+	for _, target := range relative {
+		if file := resolvePythonFile(repoPath, target); file != "" {
+			resolved = append(resolved, file)
+		}
+	}
+
+	return resolved
+}
+
+// resolvePythonFile finds the module file or package __init__ for a slash-separated module path
+func resolvePythonFile(repoPath, modPath string) string {
+	modPath = path.Clean(modPath)
+	if strings.HasPrefix(modPath, "..") {
+		return ""
+	}
+	for _, candidate := range []string{modPath + ".py", modPath + "/__init__.py"} {
+		info, err := os.Stat(filepath.Join(repoPath, filepath.FromSlash(candidate)))
+		if err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return ""
+}
@@ -0,0 +1,110 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ServerRequest is a sampling or elicitation request the server sends to the client
+type ServerRequest struct {
+	Kind string `json:"kind"` // "sampling" or "elicitation"
+	Evidence
+}
+
+// Patterns for server-to-client requests
+var (
+	// sampling/createMessage makes the client's LLM run a prompt written by the server
+	samplingCallPattern = regexp.MustCompile(`\.createMessage\s*\(|\.create_message\s*\(|\bctx\.sample\s*\(|["']sampling/createMessage["']`)
+
+	// elicitation/create asks the user to fill in a form
+	elicitationCallPattern = regexp.MustCompile(`\.elicitInput\s*\(|\.elicit\s*\(|["']elicitation/create["']`)
+
+	// String literals in JavaScript, TypeScript and Python
+	stringLiteralPattern = regexp.MustCompile(stringLiteralExpr)
+
+	// Credentials the MCP spec forbids requesting through elicitation
+	credentialElicitPattern = regexp.MustCompile(`(?i)\b(?:password|passphrase|passwd|pass_?code|pin code|api[ _-]?keys?|apikey|access[ _-]?keys?|secret[ _-]?keys?|private[ _-]?keys?|client[ _-]?secret|secrets?|(?:access|auth|api|bearer|refresh|github|session)?[ _-]?tokens?|credentials?|seed phrase|mnemonic|credit card|card number|cvv|ssn|social security|one[ -]time (?:code|password)|otp|2fa|mfa)\b`)
+)
+
+// stringLiteralExpr matches one string literal: Python triple-quoted, template, double or single quoted
+const stringLiteralExpr = "(?s:\"\"\".*?\"\"\"|'''.*?'''|`[^`]*`|\"(?:[^\"\\\\\\n]|\\\\.)*\"|'(?:[^'\\\\\\n]|\\\\.)*')"
+
+// maxRequestArgs bounds how far past a call its arguments are read
+const maxRequestArgs = 3000
+
+// scanServerRequests records sampling and elicitation calls in one file, runs the injection
+// rules over the prompt text sent through sampling, and flags elicitations for credentials
+func scanServerRequests(content, relPath string, fc FileContext, result *IntegrityResult) {
+	for _, loc := range samplingCallPattern.FindAllStringIndex(content, -1) {
+		ev := evidenceAt(content, relPath, fc, loc[0])
+		result.ServerRequests = append(result.ServerRequests, ServerRequest{Kind: "sampling", Evidence: ev})
+
+		prompt := strings.Join(stringLiterals(requestArgs(content, loc[1])), "\n")
+		for _, match := range scanForInjection(prompt) {
+			result.SamplingInjections = append(result.SamplingInjections, IntegrityFinding{
+				PatternMatched: match.Pattern,
+				Snippet:        match.Snippet,
+				Severity:       "critical",
+				FilePath:       relPath,
+				Line:           ev.Line,
+				Language:       match.Language,
+				FileContext:    fc,
+			})
+		}
+	}
+
+	for _, loc := range elicitationCallPattern.FindAllStringIndex(content, -1) {
+		ev := evidenceAt(content, relPath, fc, loc[0])
+		result.ServerRequests = append(result.ServerRequests, ServerRequest{Kind: "elicitation", Evidence: ev})
+
+		// The message and the requested schema's field names and titles
+		args := requestArgs(content, loc[1])
+		if match := credentialElicitPattern.FindString(args); match != "" {
+			result.CredentialElicitations = append(result.CredentialElicitations, IntegrityFinding{
+				PatternMatched: "credential_elicitation",
+				Snippet:        "Requests " + strings.ToLower(strings.TrimSpace(match)) + ": " + ev.Snippet,
+				Severity:       "critical",
+				FilePath:       relPath,
+				Line:           ev.Line,
+				FileContext:    fc,
+			})
+		}
+	}
+}
+
+// requestArgs returns the arguments of the call whose name ends at offset, up to the
+// matching close paren; for a bare method string it returns the text that follows
+func requestArgs(content string, offset int) string {
+	end := offset + maxRequestArgs
+	if end > len(content) {
+		end = len(content)
+	}
+	window := content[offset:end]
+	if offset == 0 || content[offset-1] != '(' {
+		return window
+	}
+
+	return untilClose(window)
+}
+
+// stringLiterals returns the unquoted string literals in code
+func stringLiterals(code string) []string {
+	literals := make([]string, 0)
+	for _, lit := range stringLiteralPattern.FindAllString(code, -1) {
+		if lit = unquoteLiteral(lit); strings.TrimSpace(lit) != "" {
+			literals = append(literals, lit)
+		}
+	}
+	return literals
+}
+
+// unquoteLiteral strips the quotes from a string literal and expands common escapes
+func unquoteLiteral(lit string) string {
+	switch {
+	case strings.HasPrefix(lit, `"""`) || strings.HasPrefix(lit, `'''`):
+		lit = lit[3 : len(lit)-3]
+	default:
+		lit = lit[1 : len(lit)-1]
+	}
+	return literalEscapes.Replace(lit)
+}
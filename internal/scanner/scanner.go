@@ -5,25 +5,48 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mcpsek/mcpsek/internal/classifier"
 	"github.com/mcpsek/mcpsek/internal/database"
+	"github.com/mcpsek/mcpsek/internal/similarity"
 )
 
 // Scanner orchestrates security scanning of MCP servers
 type Scanner struct {
 	cloneManager *CloneManager
 	db           *database.DB
+	opts         Options
+}
+
+// Options configures optional scanner behaviour
+type Options struct {
+	// ContextWeights overrides the confidence assigned to findings per file class
+	ContextWeights ContextWeights
+	// MinConfidence is the confidence a finding needs to affect check status; nil uses the default
+	MinConfidence *float64
+	// ScoreUnreachable lets findings in code not reachable from the entrypoints affect status
+	ScoreUnreachable bool
+	// DeepScan fetches history and scans every past commit for secrets
+	DeepScan bool
+	// HistoryLimits bounds deep scans
+	HistoryLimits HistoryLimits
+	// Classifier scores tool descriptions for prompt injection; nil disables it
+	Classifier *classifier.Model
+	// ClassifierThreshold is the injection probability that raises a warning
+	ClassifierThreshold float64
 }
 
 // New creates a new scanner
-func New(cloneDir string, db *database.DB) *Scanner {
+func New(cloneDir string, db *database.DB, opts Options) *Scanner {
 	return &Scanner{
 		cloneManager: NewCloneManager(cloneDir),
 		db:           db,
+		opts:         opts,
 	}
 }
 
@@ -32,13 +55,19 @@ type ScanResult struct {
 	IntegrityResult *IntegrityResult
 	AuthResult      *AuthResult
 	ExposureResult  *ExposureResult
+	Context         *ContextSummary
 	TrustScore      int
 	ToolDefinitions []*ToolDefinition
 	ToolsHash       string
+	Revision        *Revision
 	Duration        time.Duration
+	Findings        []Finding // Flattened from the check results, fingerprinted to track across scans
+
+	// Rules this scan didn't run, whose open findings it can't have fixed
+	unevaluated []string
 }
 
-// Scan performs a complete security scan of a server
+// Scan performs a complete security scan of a server's default branch
 func (s *Scanner) Scan(ctx context.Context, serverID uuid.UUID, repoURL string) (*ScanResult, error) {
 	startTime := time.Now()
 
@@ -48,6 +77,35 @@ func (s *Scanner) Scan(ctx context.Context, serverID uuid.UUID, repoURL string)
 		return nil, fmt.Errorf("clone repository: %w", err)
 	}
 
+	return s.scan(ctx, serverID, repoPath, "", startTime)
+}
+
+// ScanRef scans a specific tag, branch or commit on demand, trying each ref in turn
+// The scan is recorded with its revision but doesn't change the server's current state
+func (s *Scanner) ScanRef(ctx context.Context, serverID uuid.UUID, repoURL string, refs ...string) (*ScanResult, error) {
+	startTime := time.Now()
+
+	repoPath, ref, err := s.cloneManager.CloneRef(ctx, repoURL, refs...)
+	if err != nil {
+		return nil, fmt.Errorf("clone ref: %w", err)
+	}
+	defer os.RemoveAll(repoPath)
+
+	return s.scan(ctx, serverID, repoPath, ref, startTime)
+}
+
+// scan runs every check on a checked-out repository and stores the results
+func (s *Scanner) scan(ctx context.Context, serverID uuid.UUID, repoPath, ref string, startTime time.Time) (*ScanResult, error) {
+	// Deep scans need history beyond the shallow clone
+	if s.opts.DeepScan {
+		if err := s.cloneManager.FetchHistory(ctx, repoPath, s.opts.HistoryLimits.MaxCommits); err != nil {
+			return nil, fmt.Errorf("fetch history: %w", err)
+		}
+	}
+
+	// Classify files and detect entrypoints once for all checks
+	rc := NewRepoContext(repoPath, s.opts)
+
 	// Run all three checks in parallel
 	type checkResult struct {
 		integrity *IntegrityResult
@@ -63,7 +121,7 @@ func (s *Scanner) Scan(ctx context.Context, serverID uuid.UUID, repoURL string)
 		result := checkResult{}
 
 		// Check 1: Tool Integrity
-		integrity, tools, err := CheckIntegrity(repoPath)
+		integrity, tools, err := CheckIntegrity(rc)
 		if err != nil {
 			result.err = fmt.Errorf("integrity check: %w", err)
 			resultChan <- result
@@ -72,8 +130,11 @@ func (s *Scanner) Scan(ctx context.Context, serverID uuid.UUID, repoURL string)
 		result.integrity = integrity
 		result.tools = tools
 
+		// Injection probability per description from the statistical classifier
+		integrity.AddInjectionScores(rc, s.opts.Classifier, s.opts.ClassifierThreshold, tools)
+
 		// Check 2: Authentication Posture
-		auth, err := CheckAuth(repoPath)
+		auth, err := CheckAuth(rc)
 		if err != nil {
 			result.err = fmt.Errorf("auth check: %w", err)
 			resultChan <- result
@@ -81,8 +142,22 @@ func (s *Scanner) Scan(ctx context.Context, serverID uuid.UUID, repoURL string)
 		}
 		result.auth = auth
 
+		// Requested OAuth scopes vs. what the tools actually do
+		auth.ScopeAnalysis = AnalyzeScopes(rc, tools)
+
+		// Optional: secrets in past commits
+		if s.opts.DeepScan {
+			history, info, err := CheckHistory(ctx, rc, s.opts.HistoryLimits)
+			if err != nil {
+				result.err = fmt.Errorf("history check: %w", err)
+				resultChan <- result
+				return
+			}
+			auth.AddHistory(rc, history, info)
+		}
+
 		// Check 3: Endpoint Exposure
-		exposure, err := CheckExposure(repoPath)
+		exposure, err := CheckExposure(rc)
 		if err != nil {
 			result.err = fmt.Errorf("exposure check: %w", err)
 			resultChan <- result
@@ -90,6 +165,17 @@ func (s *Scanner) Scan(ctx context.Context, serverID uuid.UUID, repoURL string)
 		}
 		result.exposure = exposure
 
+		// Network servers must follow the MCP authorization spec
+		if exposure.IsNetwork() {
+			conformance, err := CheckAuthConformance(rc)
+			if err != nil {
+				result.err = fmt.Errorf("auth conformance check: %w", err)
+				resultChan <- result
+				return
+			}
+			auth.AddConformance(conformance)
+		}
+
 		resultChan <- result
 	}()
 
@@ -104,16 +190,39 @@ func (s *Scanner) Scan(ctx context.Context, serverID uuid.UUID, repoURL string)
 		return nil, ctx.Err()
 	}
 
+	// Tools that shadow or reference other servers' tools; the index is best effort
+	unevaluated := make([]string, 0)
+	if err := s.checkShadowing(ctx, rc, serverID, result.integrity, result.tools); err != nil {
+		fmt.Printf("Warning: shadowing check failed: %v\n", err)
+		unevaluated = append(unevaluated, "name_collision", "cross_reference")
+	}
+	if !s.opts.DeepScan {
+		unevaluated = append(unevaluated, "history_secret")
+	}
+	if s.opts.Classifier == nil {
+		unevaluated = append(unevaluated, "classifier")
+	}
+
 	// Compute trust score
 	trustScore := ComputeTrustScore(
 		result.integrity.Status,
 		result.auth.Status,
 		result.exposure.Status,
+		ScopePenalty(result.auth.ScopeAnalysis),
 	)
 
 	// Compute tools hash
 	toolsHash := computeToolsHash(result.tools)
 
+	// What was analysed
+	revision := &Revision{Ref: ref, PackageVersion: detectPackageVersion(repoPath)}
+	if commitSHA, branch, err := s.cloneManager.Revision(ctx, repoPath); err != nil {
+		fmt.Printf("Warning: reading revision failed: %v\n", err)
+	} else {
+		revision.CommitSHA = commitSHA
+		revision.Branch = branch
+	}
+
 	// Duration
 	duration := time.Since(startTime)
 
@@ -121,11 +230,15 @@ func (s *Scanner) Scan(ctx context.Context, serverID uuid.UUID, repoURL string)
 		IntegrityResult: result.integrity,
 		AuthResult:      result.auth,
 		ExposureResult:  result.exposure,
+		Context:         rc.Summary(),
 		TrustScore:      trustScore,
 		ToolDefinitions: result.tools,
 		ToolsHash:       toolsHash,
+		Revision:        revision,
 		Duration:        duration,
+		unevaluated:     unevaluated,
 	}
+	scanResult.Findings = CollectFindings(rc, scanResult)
 
 	// Store scan results in database
 	if err := s.storeScanResults(ctx, serverID, scanResult); err != nil {
@@ -135,6 +248,20 @@ func (s *Scanner) Scan(ctx context.Context, serverID uuid.UUID, repoURL string)
 	return scanResult, nil
 }
 
+// checkShadowing compares a server's tools with the ecosystem-wide tool index
+func (s *Scanner) checkShadowing(ctx context.Context, rc *RepoContext, serverID uuid.UUID, integrity *IntegrityResult, tools []*ToolDefinition) error {
+	server, err := s.db.GetServer(ctx, serverID)
+	if err != nil {
+		return fmt.Errorf("get server: %w", err)
+	}
+	index, err := s.db.GetToolIndex(ctx)
+	if err != nil {
+		return fmt.Errorf("get tool index: %w", err)
+	}
+	integrity.AddShadowing(rc, FindShadowing(server, tools, index))
+	return nil
+}
+
 // storeScanResults saves scan results to the database
 func (s *Scanner) storeScanResults(ctx context.Context, serverID uuid.UUID, result *ScanResult) error {
 	// Convert results to JSON
@@ -153,18 +280,28 @@ func (s *Scanner) storeScanResults(ctx context.Context, serverID uuid.UUID, resu
 		return fmt.Errorf("convert exposure result: %w", err)
 	}
 
+	contextJSON, err := result.Context.ToJSON()
+	if err != nil {
+		return fmt.Errorf("convert context summary: %w", err)
+	}
+
 	// Insert scan record
 	scan := &database.Scan{
-		ServerID:               serverID,
-		ToolIntegrityStatus:    result.IntegrityResult.Status,
-		ToolIntegrityDetails:   integrityJSON,
-		AuthStatus:             result.AuthResult.Status,
-		AuthDetails:            authJSON,
-		ExposureStatus:         result.ExposureResult.Status,
-		ExposureDetails:        exposureJSON,
-		TrustScore:             result.TrustScore,
-		ToolDefinitionsHash:    &result.ToolsHash,
-		ScanDurationMs:         intPtr(int(result.Duration.Milliseconds())),
+		ServerID:             serverID,
+		ToolIntegrityStatus:  result.IntegrityResult.Status,
+		ToolIntegrityDetails: integrityJSON,
+		AuthStatus:           result.AuthResult.Status,
+		AuthDetails:          authJSON,
+		ExposureStatus:       result.ExposureResult.Status,
+		ExposureDetails:      exposureJSON,
+		ContextDetails:       contextJSON,
+		TrustScore:           result.TrustScore,
+		ToolDefinitionsHash:  &result.ToolsHash,
+		ScanDurationMs:       intPtr(int(result.Duration.Milliseconds())),
+		CommitSHA:            optionalStr(result.Revision.CommitSHA),
+		Branch:               optionalStr(result.Revision.Branch),
+		Ref:                  optionalStr(result.Revision.Ref),
+		PackageVersion:       optionalStr(result.Revision.PackageVersion),
 	}
 
 	if err := s.db.InsertScan(ctx, scan); err != nil {
@@ -179,44 +316,105 @@ func (s *Scanner) storeScanResults(ctx context.Context, serverID uuid.UUID, resu
 			params, _ = json.Marshal(tool.Parameters)
 		}
 
+		// Tools without outputs get the empty hash, so a NULL hash only marks tools stored before
+		// outputs were tracked, and outputs added later are caught as mutations
+		var outputs json.RawMessage
+		outputHash := strPtr(emptyOutputHash)
+		if len(tool.Outputs) > 0 {
+			outputs, _ = json.Marshal(tool.Outputs)
+			outputHash = strPtr(tool.OutputHash)
+		}
+
 		dbTools[i] = &database.ToolDefinition{
 			ServerID:    serverID,
 			ToolName:    tool.Name,
 			Description: strPtr(tool.Description),
 			Parameters:  params,
 			ContentHash: tool.Hash,
+			Outputs:     outputs,
+			OutputHash:  outputHash,
+			MinHash:     similarity.Compute(tool.Description).Int64s(),
 		}
 	}
 
-	if err := s.db.InsertToolDefinitions(ctx, dbTools); err != nil {
+	if err := s.db.InsertToolDefinitions(ctx, scan.ID, dbTools); err != nil {
 		return fmt.Errorf("insert tool definitions: %w", err)
 	}
 
+	// Scans of other refs are history; the server's tools and state follow its default branch
+	if result.Revision.Ref != "" {
+		return nil
+	}
+
 	// Update server record
 	transport := result.ExposureResult.Transport
-	if err := s.db.UpdateServerAfterScan(ctx, serverID, result.TrustScore, len(result.ToolDefinitions), transport); err != nil {
+	transports := result.ExposureResult.Transports
+	if err := s.db.UpdateServerAfterScan(ctx, serverID, result.TrustScore, len(result.ToolDefinitions), transport, transports); err != nil {
 		return fmt.Errorf("update server: %w", err)
 	}
 
+	var installCommand *string
+	var installWarnings []string
+	if install := result.ExposureResult.InstallCommand; install != nil {
+		installCommand = &install.Command
+		installWarnings = install.Warnings
+	}
+	if err := s.db.UpdateServerInstallCommand(ctx, serverID, installCommand, installWarnings); err != nil {
+		return fmt.Errorf("update install command: %w", err)
+	}
+
+	if err := s.db.UpdateServerMutationRisk(ctx, serverID, result.IntegrityResult.MutationRisk); err != nil {
+		return fmt.Errorf("update mutation risk: %w", err)
+	}
+
+	if err := s.recordFindings(ctx, serverID, scan.ID, result); err != nil {
+		return fmt.Errorf("record findings: %w", err)
+	}
+
 	// Check for mutations
-	if err := s.detectMutations(ctx, serverID, result.ToolDefinitions); err != nil {
+	if err := s.detectMutations(ctx, serverID, scan.ID, result.ToolDefinitions); err != nil {
 		// Log error but don't fail the scan
 		fmt.Printf("Warning: mutation detection failed: %v\n", err)
 	}
 
+	// Near-duplicates of critical tools on other servers get rescanned and reviewed
+	if err := s.flagSimilarTools(ctx, serverID, dbTools); err != nil {
+		fmt.Printf("Warning: similarity flagging failed: %v\n", err)
+	}
+
 	return nil
 }
 
-// detectMutations compares current tools with previous scan to detect changes
-func (s *Scanner) detectMutations(ctx context.Context, serverID uuid.UUID, currentTools []*ToolDefinition) error {
-	// Get previous tool definitions
-	previousTools, err := s.db.GetToolDefinitionsForServer(ctx, serverID)
+// recordFindings updates the lifecycle of the server's findings with what a scan found
+func (s *Scanner) recordFindings(ctx context.Context, serverID, scanID uuid.UUID, result *ScanResult) error {
+	findings := make([]*database.Finding, len(result.Findings))
+	for i, f := range result.Findings {
+		findings[i] = &database.Finding{
+			ServerID:    serverID,
+			Fingerprint: f.Fingerprint,
+			Check:       f.Check,
+			Rule:        f.Rule,
+			Severity:    f.Severity,
+			ToolName:    optionalStr(f.ToolName),
+			FilePath:    optionalStr(f.FilePath),
+			Message:     f.Message,
+		}
+		if f.Line > 0 {
+			findings[i].Line = intPtr(f.Line)
+		}
+	}
+	return s.db.RecordFindings(ctx, serverID, scanID, findings, result.unevaluated)
+}
+
+// detectMutations compares a scan's tools with the snapshot of the scan before it
+func (s *Scanner) detectMutations(ctx context.Context, serverID, scanID uuid.UUID, currentTools []*ToolDefinition) error {
+	previousScanID, previousTools, err := s.db.GetPreviousToolSnapshot(ctx, serverID, scanID)
 	if err != nil {
 		return err
 	}
 
-	// If no previous tools, nothing to compare
-	if len(previousTools) == 0 {
+	// If no previous snapshot, nothing to compare
+	if previousScanID == nil {
 		return nil
 	}
 
@@ -242,35 +440,73 @@ func (s *Scanner) detectMutations(ctx context.Context, serverID uuid.UUID, curre
 			mutations = append(mutations, &database.Mutation{
 				ServerID:       serverID,
 				ToolName:       name,
+				Kind:           "description",
 				OldHash:        prevTool.ContentHash,
 				NewHash:        "(removed)",
 				OldDescription: prevTool.Description,
 				Severity:       "warning",
 				SeverityReason: strPtr("Tool was removed"),
 			})
-		} else if prevTool.ContentHash != currTool.Hash {
-			// Tool modified
-			severity, reason := assessMutationSeverity(prevTool.Description, strPtr(currTool.Description))
-
+		} else if prevTool.ContentHash != currTool.Hash && !sameNormalized(prevTool, currTool) {
+			// Tool modified; rated by what the new version added
 			var prevParams, currParams json.RawMessage
 			prevParams = prevTool.Parameters
 			if currTool.Parameters != nil {
 				currParams, _ = json.Marshal(currTool.Parameters)
 			}
 
-			mutations = append(mutations, &database.Mutation{
+			mutation := &database.Mutation{
 				ServerID:       serverID,
 				ToolName:       name,
+				Kind:           "description",
 				OldHash:        prevTool.ContentHash,
 				NewHash:        currTool.Hash,
 				OldDescription: prevTool.Description,
 				NewDescription: strPtr(currTool.Description),
 				OldParameters:  prevParams,
 				NewParameters:  currParams,
-				Severity:       severity,
-				SeverityReason: strPtr(reason),
-			})
+			}
+			d, diffJSON := s.diffMutation(mutation)
+			severity, reason := d.Severity()
+			mutation.Severity = severity
+			mutation.SeverityReason = strPtr(reason)
+			mutation.Diff = diffJSON
+			mutations = append(mutations, mutation)
+		}
+	}
+
+	// Check for changed outputs; tools stored before outputs were tracked have no output hash
+	for name, prevTool := range prevMap {
+		currTool, exists := currMap[name]
+		if !exists || prevTool.OutputHash == nil || *prevTool.OutputHash == orDefault(currTool.OutputHash, emptyOutputHash) {
+			continue
+		}
+
+		oldHash := *prevTool.OutputHash
+		if oldHash == emptyOutputHash {
+			oldHash = "(none)"
+		}
+
+		var prevOutputs []ToolOutput
+		json.Unmarshal(prevTool.Outputs, &prevOutputs)
+		severity, reason := assessOutputMutationSeverity(prevOutputs, currTool.Outputs)
+
+		var currOutputs json.RawMessage
+		if len(currTool.Outputs) > 0 {
+			currOutputs, _ = json.Marshal(currTool.Outputs)
 		}
+
+		mutations = append(mutations, &database.Mutation{
+			ServerID:       serverID,
+			ToolName:       name,
+			Kind:           "output",
+			OldHash:        oldHash,
+			NewHash:        orDefault(currTool.OutputHash, "(none)"),
+			OldOutputs:     prevTool.Outputs,
+			NewOutputs:     currOutputs,
+			Severity:       severity,
+			SeverityReason: strPtr(reason),
+		})
 	}
 
 	// Check for added tools
@@ -279,6 +515,7 @@ func (s *Scanner) detectMutations(ctx context.Context, serverID uuid.UUID, curre
 			mutations = append(mutations, &database.Mutation{
 				ServerID:       serverID,
 				ToolName:       name,
+				Kind:           "description",
 				OldHash:        "(none)",
 				NewHash:        currTool.Hash,
 				NewDescription: strPtr(currTool.Description),
@@ -290,6 +527,11 @@ func (s *Scanner) detectMutations(ctx context.Context, serverID uuid.UUID, curre
 
 	// Insert mutation records
 	for _, mutation := range mutations {
+		mutation.ScanID = &scanID
+		mutation.PreviousScanID = previousScanID
+		if mutation.Diff == nil {
+			_, mutation.Diff = s.diffMutation(mutation)
+		}
 		if err := s.db.InsertMutation(ctx, mutation); err != nil {
 			return err
 		}
@@ -298,32 +540,55 @@ func (s *Scanner) detectMutations(ctx context.Context, serverID uuid.UUID, curre
 	return nil
 }
 
-// assessMutationSeverity determines severity based on description changes
-func assessMutationSeverity(oldDesc, newDesc *string) (string, string) {
-	if oldDesc == nil || newDesc == nil {
-		return "info", "Description changed"
+// sameNormalized reports whether a stored tool differs from the current one only because it was
+// hashed before descriptions were unescaped and dedented
+func sameNormalized(prev *database.ToolDefinition, curr *ToolDefinition) bool {
+	if prev.Description == nil {
+		return false
+	}
+	stored := *prev.Description
+	if len(stored) <= maxDescriptionLen {
+		return computeHash(prev.ToolName, sourceDescription(stored)) == curr.Hash
 	}
 
-	oldLen := len(*oldDesc)
-	newLen := len(*newDesc)
+	// Long descriptions are stored truncated, so the hash can't be recomputed. A stored prefix that
+	// normalization leaves alone was already normalized, and a differing hash is a real change
+	prefix := strings.TrimSuffix(stored, "...")
+	normalized := sourceDescription(prefix)
+	if normalized == prefix {
+		return false
+	}
 
-	// Check for suspicious indicators in new description
-	if hiddenInstructionPattern.MatchString(*newDesc) {
-		return "critical", "New description contains hidden instruction tags"
+	// The cut may have split an escape sequence
+	normalized = strings.TrimRight(normalized, "\\")
+	current := curr.Description
+	if len(current) > maxDescriptionLen {
+		current = strings.TrimSuffix(current, "...")
+	}
+	if len(normalized) > len(current) {
+		return strings.HasPrefix(normalized, current)
 	}
+	return strings.HasPrefix(current, normalized)
+}
 
-	for _, pattern := range fileExfiltrationPatterns {
-		if pattern.MatchString(*newDesc) && !pattern.MatchString(*oldDesc) {
-			return "critical", "New description contains file exfiltration patterns"
+// assessOutputMutationSeverity determines severity based on changes to what a tool returns
+func assessOutputMutationSeverity(oldOutputs, newOutputs []ToolOutput) (string, string) {
+	seen := make(map[string]bool)
+	for _, output := range oldOutputs {
+		for _, match := range scanForInjection(output.Text) {
+			seen[match.Pattern] = true
 		}
 	}
 
-	// Check for significant length increase
-	if newLen > oldLen+200 {
-		return "warning", fmt.Sprintf("Description grew by %d characters", newLen-oldLen)
+	for _, output := range newOutputs {
+		for _, match := range scanForInjection(output.Text) {
+			if !seen[match.Pattern] {
+				return "critical", fmt.Sprintf("New output contains %s patterns", strings.ReplaceAll(match.Pattern, "_", " "))
+			}
+		}
 	}
 
-	return "info", "Minor description change"
+	return "info", "Tool output changed"
 }
 
 // computeToolsHash computes a hash of all tool definitions
@@ -359,3 +624,17 @@ func intPtr(i int) *int {
 func strPtr(s string) *string {
 	return &s
 }
+
+func optionalStr(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,484 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Capability levels, ordered from least to most privileged
+const (
+	CapabilityNone   = ""
+	CapabilityRead   = "read"
+	CapabilityWrite  = "write"
+	CapabilityDelete = "delete"
+	CapabilityAdmin  = "admin"
+)
+
+// capabilityRank orders capability levels
+var capabilityRank = map[string]int{
+	CapabilityNone:   0,
+	CapabilityRead:   1,
+	CapabilityWrite:  2,
+	CapabilityDelete: 3,
+	CapabilityAdmin:  4,
+}
+
+// ProviderScopeAnalysis compares the scopes requested for one provider with what the tools need
+type ProviderScopeAnalysis struct {
+	Provider        string           `json:"provider"` // "github", "google", "slack"
+	RequestedScopes []RequestedScope `json:"requested_scopes"`
+	ToolCapability  string           `json:"tool_capability"` // Highest capability any tool needs
+	ToolsConsidered int              `json:"tools_considered"`
+	ExcessScopes    []string         `json:"excess_scopes,omitempty"`
+	Status          string           `json:"status"` // "pass", "warning", "critical"
+}
+
+// RequestedScope is one OAuth scope or token permission found in the repository
+type RequestedScope struct {
+	Scope      string     `json:"scope"`
+	Capability string     `json:"capability"`
+	Evidence   []Evidence `json:"evidence"`
+}
+
+// scopeProvider describes how to find and grade one provider's scopes
+type scopeProvider struct {
+	Name     string
+	Mention  *regexp.Regexp // Files must mention the provider for ambiguous scopes to count
+	Extract  func(line string, lineContext, fileContext bool) []string
+	Grade    func(scope string) string
+	Keywords []string // Words that tie a tool to this provider
+}
+
+// Patterns for scope extraction
+var (
+	// Lines that talk about scopes or token permissions
+	scopeContextPattern = regexp.MustCompile(`(?i)scope|permission|_SCOPES?\s*=`)
+
+	// Files that document fine-grained token permissions
+	permissionDocPattern = regexp.MustCompile(`(?i)fine-grained|permissions`)
+
+	// Quoted or backticked tokens, and values of scope= parameters and *_SCOPES= env vars
+	scopeQuotedPattern = regexp.MustCompile("[\"'`]([A-Za-z0-9_:.\\- ,+]+)[\"'`]")
+	scopeParamPattern  = regexp.MustCompile(`(?i)(?:scope|_SCOPES?)\s*[=:]\s*([A-Za-z0-9_:.\-,+%]+)`)
+
+	// GitHub fine-grained token permissions, e.g. "Contents: Read and write"
+	githubFineGrainedPattern = regexp.MustCompile(`(?i)\b(contents|issues|pull requests|metadata|actions|administration|workflows|secrets|pages|deployments|webhooks|members|checks|commit statuses|discussions|packages|environments)\b\s*[:(\-–]\s*(read[- ]only|read\s*(?:and|&|/)\s*write|read|write|admin)`)
+
+	googleScopePattern = regexp.MustCompile(`https://www\.googleapis\.com/auth/[A-Za-z0-9._\-/]+|https://mail\.google\.com/`)
+
+	slackScopePattern = regexp.MustCompile(`\b((?:admin(?:\.[a-z_]+)*|app_mentions|bookmarks|calls|canvases|channels|chat|commands|conversations\.connect|dnd|emoji|files|groups|im|incoming-webhook|links|metadata\.message|mpim|pins|reactions|reminders|remote_files|search|team|usergroups|users|users\.profile)(?::[a-z_.]+))\b`)
+
+	githubMentionPattern = regexp.MustCompile(`(?i)github`)
+	slackMentionPattern  = regexp.MustCompile(`(?i)slack`)
+	googleMentionPattern = regexp.MustCompile(`(?i)google|googleapis`)
+)
+
+// githubScopes grades GitHub classic OAuth scopes
+var githubScopes = map[string]string{
+	"repo":               CapabilityWrite,
+	"repo:status":        CapabilityWrite,
+	"repo_deployment":    CapabilityWrite,
+	"public_repo":        CapabilityWrite,
+	"repo:invite":        CapabilityWrite,
+	"security_events":    CapabilityWrite,
+	"workflow":           CapabilityWrite,
+	"write:packages":     CapabilityWrite,
+	"read:packages":      CapabilityRead,
+	"delete:packages":    CapabilityDelete,
+	"admin:org":          CapabilityAdmin,
+	"write:org":          CapabilityWrite,
+	"read:org":           CapabilityRead,
+	"manage_runners:org": CapabilityAdmin,
+	"admin:public_key":   CapabilityAdmin,
+	"write:public_key":   CapabilityWrite,
+	"read:public_key":    CapabilityRead,
+	"admin:repo_hook":    CapabilityAdmin,
+	"write:repo_hook":    CapabilityWrite,
+	"read:repo_hook":     CapabilityRead,
+	"admin:org_hook":     CapabilityAdmin,
+	"gist":               CapabilityWrite,
+	"notifications":      CapabilityRead,
+	"user":               CapabilityWrite,
+	"read:user":          CapabilityRead,
+	"user:email":         CapabilityRead,
+	"user:follow":        CapabilityWrite,
+	"project":            CapabilityWrite,
+	"read:project":       CapabilityRead,
+	"delete_repo":        CapabilityDelete,
+	"write:discussion":   CapabilityWrite,
+	"read:discussion":    CapabilityRead,
+	"admin:enterprise":   CapabilityAdmin,
+	"admin:gpg_key":      CapabilityAdmin,
+	"write:gpg_key":      CapabilityWrite,
+	"read:gpg_key":       CapabilityRead,
+	"codespace":          CapabilityWrite,
+	"audit_log":          CapabilityAdmin,
+	"read:audit_log":     CapabilityRead,
+	"copilot":            CapabilityAdmin,
+}
+
+// scopeProviders is the catalogue of providers analysed
+var scopeProviders = []scopeProvider{
+	{
+		Name:     "github",
+		Mention:  githubMentionPattern,
+		Extract:  extractGitHubScopes,
+		Grade:    gradeGitHubScope,
+		Keywords: []string{"github", "repo", "issue", "pull", "gist", "commit", "branch"},
+	},
+	{
+		Name:     "google",
+		Mention:  googleMentionPattern,
+		Extract:  extractGoogleScopes,
+		Grade:    gradeGoogleScope,
+		Keywords: []string{"google", "gmail", "drive", "calendar", "sheet", "spreadsheet", "doc", "email"},
+	},
+	{
+		Name:     "slack",
+		Mention:  slackMentionPattern,
+		Extract:  extractSlackScopes,
+		Grade:    gradeSlackScope,
+		Keywords: []string{"slack", "channel", "message", "thread", "reaction"},
+	},
+}
+
+// Words that reveal a tool's capability
+var (
+	adminVerbs  = []string{"admin", "administer", "manage"}
+	adminNouns  = []string{"permission", "collaborator", "member", "role", "webhook", "hook", "secret", "setting", "transfer", "visibility", "protection"}
+	deleteVerbs = []string{"delete", "remove", "destroy", "purge", "trash", "revoke", "drop", "erase", "kick"}
+	writeVerbs  = []string{"create", "update", "write", "add", "post", "send", "edit", "merge", "push", "comment", "set", "upload", "close", "reopen", "assign", "fork", "star", "label", "react", "publish", "modify", "move", "rename", "reply", "schedule", "invite", "approve", "submit", "patch", "put", "insert", "append", "draft", "mark", "archive"}
+	readVerbs   = []string{"get", "list", "search", "read", "fetch", "find", "view", "show", "query", "describe", "lookup", "check", "download"}
+)
+
+// AnalyzeScopes extracts requested OAuth scopes per provider and flags scopes beyond what the tools need
+func AnalyzeScopes(rc *RepoContext, tools []*ToolDefinition) []ProviderScopeAnalysis {
+	requested := make(map[string]map[string]*RequestedScope)
+
+	filepath.Walk(rc.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if skipDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isScopeSource(info.Name()) {
+			return nil
+		}
+
+		relPath := rc.RelPath(path)
+		fc := rc.Context(relPath)
+		// Docs and .env.example are authoritative here: they tell users which scopes to grant
+		if fc.FileClass == ClassTest || fc.FileClass == ClassVendored || fc.FileClass == ClassGenerated {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil || isBinaryContent(string(content)) {
+			return nil
+		}
+		contentStr := string(content)
+		fileContext := permissionDocPattern.MatchString(contentStr)
+
+		lineStart := 0
+		for _, line := range strings.SplitAfter(contentStr, "\n") {
+			lineContext := scopeContextPattern.MatchString(line)
+			for _, provider := range scopeProviders {
+				if !provider.Mention.MatchString(contentStr) && !lineContext {
+					continue
+				}
+				for _, scope := range provider.Extract(line, lineContext, fileContext) {
+					capability := provider.Grade(scope)
+					if capability == CapabilityNone {
+						continue
+					}
+					if requested[provider.Name] == nil {
+						requested[provider.Name] = make(map[string]*RequestedScope)
+					}
+					rs, ok := requested[provider.Name][scope]
+					if !ok {
+						rs = &RequestedScope{Scope: scope, Capability: capability, Evidence: make([]Evidence, 0)}
+						requested[provider.Name][scope] = rs
+					}
+					if len(rs.Evidence) < 3 {
+						rs.Evidence = append(rs.Evidence, evidenceAt(contentStr, relPath, fc, lineStart))
+					}
+				}
+			}
+			lineStart += len(line)
+		}
+		return nil
+	})
+
+	analyses := make([]ProviderScopeAnalysis, 0)
+	for _, provider := range scopeProviders {
+		scopes, ok := requested[provider.Name]
+		if !ok {
+			continue
+		}
+
+		providerTools := toolsForProvider(tools, provider)
+		needed := CapabilityNone
+		for _, tool := range providerTools {
+			if c := ToolCapability(tool); capabilityRank[c] > capabilityRank[needed] {
+				needed = c
+			}
+		}
+
+		analysis := ProviderScopeAnalysis{
+			Provider:        provider.Name,
+			RequestedScopes: make([]RequestedScope, 0, len(scopes)),
+			ToolCapability:  needed,
+			ToolsConsidered: len(providerTools),
+			Status:          "pass",
+		}
+		for _, rs := range scopes {
+			analysis.RequestedScopes = append(analysis.RequestedScopes, *rs)
+		}
+		sort.Slice(analysis.RequestedScopes, func(i, j int) bool {
+			return analysis.RequestedScopes[i].Scope < analysis.RequestedScopes[j].Scope
+		})
+
+		// Without extracted tools there's nothing to compare against
+		if len(providerTools) > 0 {
+			for _, rs := range analysis.RequestedScopes {
+				if capabilityRank[rs.Capability] <= capabilityRank[needed] {
+					continue
+				}
+				analysis.ExcessScopes = append(analysis.ExcessScopes, rs.Scope)
+				// Admin or delete rights on a server that never uses them
+				if capabilityRank[rs.Capability] >= capabilityRank[CapabilityDelete] {
+					analysis.Status = "critical"
+				} else {
+					analysis.Status = escalateStatus(analysis.Status, "warning")
+				}
+			}
+		}
+
+		analyses = append(analyses, analysis)
+	}
+
+	return analyses
+}
+
+// ToolCapability infers the most privileged action a tool performs from its name and description
+func ToolCapability(tool *ToolDefinition) string {
+	nameWords := splitIdentifier(tool.Name)
+	// The name is the strongest signal; fall back to the first sentence of the description
+	descWords := splitIdentifier(firstSentence(tool.Description))
+
+	for _, words := range [][]string{nameWords, descWords} {
+		deletes := containsAnyWord(words, deleteVerbs)
+		mutates := deletes || containsAnyWord(words, writeVerbs)
+		switch {
+		case containsAnyWord(words, adminVerbs), mutates && containsAnyWord(words, adminNouns):
+			return CapabilityAdmin
+		case deletes:
+			return CapabilityDelete
+		case mutates:
+			return CapabilityWrite
+		case containsAnyWord(words, readVerbs):
+			return CapabilityRead
+		}
+	}
+	return CapabilityRead
+}
+
+// ScopePenalty returns the trust score penalty for over-privileged scopes
+func ScopePenalty(analyses []ProviderScopeAnalysis) int {
+	status := "pass"
+	for _, a := range analyses {
+		status = escalateStatus(status, a.Status)
+	}
+	switch status {
+	case "critical":
+		return 15
+	case "warning":
+		return 5
+	}
+	return 0
+}
+
+// toolsForProvider returns the tools that mention the provider, or all tools when none do
+func toolsForProvider(tools []*ToolDefinition, provider scopeProvider) []*ToolDefinition {
+	matched := make([]*ToolDefinition, 0)
+	for _, tool := range tools {
+		text := strings.ToLower(tool.Name + " " + tool.Description)
+		for _, keyword := range provider.Keywords {
+			if strings.Contains(text, keyword) {
+				matched = append(matched, tool)
+				break
+			}
+		}
+	}
+	if len(matched) == 0 {
+		return tools
+	}
+	return matched
+}
+
+// isScopeSource reports whether a file may declare scopes: code, config, docs and .env.example
+func isScopeSource(name string) bool {
+	lower := strings.ToLower(name)
+	if scanExtensions[filepath.Ext(lower)] {
+		return true
+	}
+	switch filepath.Ext(lower) {
+	case ".md", ".mdx", ".rst", ".txt", ".toml":
+		return true
+	}
+	return strings.HasPrefix(lower, ".env.") && lower != ".env.local"
+}
+
+// extractGitHubScopes finds classic scopes in scope-related lines and fine-grained permissions in files documenting them
+func extractGitHubScopes(line string, lineContext, fileContext bool) []string {
+	scopes := make([]string, 0)
+	if lineContext {
+		for _, candidate := range scopeCandidates(line) {
+			if _, ok := githubScopes[candidate]; ok {
+				scopes = append(scopes, candidate)
+			}
+		}
+	}
+	if !fileContext && !lineContext {
+		return scopes
+	}
+	for _, m := range githubFineGrainedPattern.FindAllStringSubmatch(line, -1) {
+		name := strings.ReplaceAll(strings.ToLower(m[1]), " ", "_")
+		access := strings.ToLower(m[2])
+		switch {
+		case strings.Contains(access, "write"):
+			access = "write"
+		case strings.Contains(access, "admin"):
+			access = "admin"
+		default:
+			access = "read"
+		}
+		scopes = append(scopes, name+":"+access)
+	}
+	return scopes
+}
+
+// gradeGitHubScope returns the capability a GitHub scope or fine-grained permission grants
+func gradeGitHubScope(scope string) string {
+	if c, ok := githubScopes[scope]; ok {
+		return c
+	}
+	name, access, ok := strings.Cut(scope, ":")
+	if !ok {
+		return CapabilityNone
+	}
+	if name == "administration" || name == "secrets" || name == "members" || access == "admin" {
+		if access == "read" {
+			return CapabilityRead
+		}
+		return CapabilityAdmin
+	}
+	if access == "write" {
+		return CapabilityWrite
+	}
+	return CapabilityRead
+}
+
+// extractGoogleScopes finds Google OAuth scope URLs
+func extractGoogleScopes(line string, _, _ bool) []string {
+	return googleScopePattern.FindAllString(line, -1)
+}
+
+// gradeGoogleScope returns the capability a Google scope URL grants
+func gradeGoogleScope(scope string) string {
+	if scope == "https://mail.google.com/" {
+		return CapabilityDelete // Full Gmail access including permanent deletion
+	}
+	name := strings.TrimPrefix(scope, "https://www.googleapis.com/auth/")
+	switch {
+	case strings.HasPrefix(name, "admin.") || name == "cloud-platform" || strings.HasPrefix(name, "cloud-platform."):
+		if strings.HasSuffix(name, ".readonly") {
+			return CapabilityRead
+		}
+		return CapabilityAdmin
+	case strings.HasSuffix(name, ".readonly") || strings.HasSuffix(name, ".metadata") ||
+		strings.HasPrefix(name, "userinfo.") || name == "openid":
+		return CapabilityRead
+	}
+	return CapabilityWrite
+}
+
+// extractSlackScopes finds Slack OAuth scopes
+func extractSlackScopes(line string, _, _ bool) []string {
+	return slackScopePattern.FindAllString(line, -1)
+}
+
+// gradeSlackScope returns the capability a Slack scope grants
+func gradeSlackScope(scope string) string {
+	name, access, _ := strings.Cut(scope, ":")
+	switch {
+	case strings.HasPrefix(name, "admin"):
+		return CapabilityAdmin
+	case access == "read" || access == "history" || strings.HasPrefix(access, "read."):
+		return CapabilityRead
+	}
+	return CapabilityWrite
+}
+
+// scopeCandidates splits quoted strings and scope parameters into individual scope tokens
+func scopeCandidates(line string) []string {
+	raw := make([]string, 0)
+	for _, m := range scopeQuotedPattern.FindAllStringSubmatch(line, -1) {
+		raw = append(raw, m[1])
+	}
+	for _, m := range scopeParamPattern.FindAllStringSubmatch(line, -1) {
+		raw = append(raw, m[1])
+	}
+
+	candidates := make([]string, 0)
+	for _, r := range raw {
+		r = strings.ReplaceAll(r, "%20", " ")
+		for _, token := range strings.FieldsFunc(r, func(c rune) bool {
+			return c == ' ' || c == ',' || c == '+'
+		}) {
+			candidates = append(candidates, token)
+		}
+	}
+	return candidates
+}
+
+// splitIdentifier lowercases and splits snake_case, kebab-case, camelCase and prose into words
+func splitIdentifier(s string) []string {
+	var b strings.Builder
+	for i, c := range s {
+		if c >= 'A' && c <= 'Z' && i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(c)
+	}
+	return strings.FieldsFunc(strings.ToLower(b.String()), func(c rune) bool {
+		return !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9')
+	})
+}
+
+// firstSentence returns the text up to the first sentence break
+func firstSentence(s string) string {
+	if i := strings.IndexAny(s, ".\n"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// containsAnyWord reports whether words contains any of the verbs, allowing plural/tense suffixes
+func containsAnyWord(words, verbs []string) bool {
+	for _, w := range words {
+		for _, v := range verbs {
+			if w == v || w == v+"s" || w == v+"es" || w == v+"d" || w == v+"ed" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -1,8 +1,9 @@
 package scanner
 
 // ComputeTrustScore calculates the trust score based on three check results
-// Score starts at 100 and subtracts penalties
-func ComputeTrustScore(integrity, auth, exposure string) int {
+// Score starts at 100 and subtracts penalties, including any extra penalties
+// such as ScopePenalty
+func ComputeTrustScore(integrity, auth, exposure string, penalties ...int) int {
 	score := 100
 
 	// Check 1: Tool Integrity
@@ -29,6 +30,11 @@ func ComputeTrustScore(integrity, auth, exposure string) int {
 		score -= 10
 	}
 
+	// Additional findings
+	for _, penalty := range penalties {
+		score -= penalty
+	}
+
 	// Floor at 0, cap at 100
 	if score < 0 {
 		score = 0
@@ -39,3 +45,18 @@ func ComputeTrustScore(integrity, auth, exposure string) int {
 
 	return score
 }
+
+// statusRank orders check statuses by severity
+var statusRank = map[string]int{
+	"pass":     0,
+	"warning":  1,
+	"critical": 2,
+}
+
+// escalateStatus returns the more severe of two statuses
+func escalateStatus(current, candidate string) string {
+	if statusRank[candidate] > statusRank[current] {
+		return candidate
+	}
+	return current
+}
@@ -0,0 +1,342 @@
+package scanner
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"hash/crc32"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// secretRule describes one provider's secret format
+type secretRule struct {
+	ID       string         // Reported as SecretFinding.SecretType
+	Provider string         // Service the secret belongs to
+	Pattern  *regexp.Regexp // Capture group 1 is the secret, or the whole match if there is no group
+	// Validate performs offline validation (checksum or structure). A false result discards the match.
+	Validate func(secret string) bool
+	// Checksum marks validators that verify an embedded checksum rather than just the shape
+	Checksum bool
+	// MinEntropy discards low-entropy matches such as placeholders when non-zero
+	MinEntropy float64
+}
+
+// secretRules is the provider catalogue, ordered from most to least specific
+var secretRules = []*secretRule{
+	// Cloud providers
+	{ID: "aws_key", Provider: "aws", Pattern: regexp.MustCompile(`\b((?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16})\b`)},
+	{ID: "aws_secret_key", Provider: "aws", Pattern: regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`), MinEntropy: 4.0},
+	{ID: "google_api_key", Provider: "google", Pattern: regexp.MustCompile(`\b(AIza[0-9A-Za-z_-]{35})\b`)},
+	{ID: "google_oauth_client_secret", Provider: "google", Pattern: regexp.MustCompile(`\b(GOCSPX-[A-Za-z0-9_-]{28})\b`)},
+	{ID: "gcp_service_account", Provider: "google", Pattern: regexp.MustCompile(`"private_key_id"\s*:\s*"([a-f0-9]{40})"`)},
+	{ID: "azure_storage_key", Provider: "azure", Pattern: regexp.MustCompile(`AccountKey=([A-Za-z0-9+/]{86}==)`), Validate: validateBase64Length(64)},
+	{ID: "azure_client_secret", Provider: "azure", Pattern: regexp.MustCompile(`\b([A-Za-z0-9_~.-]{3}\dQ~[A-Za-z0-9_~.-]{31,34})\b`), MinEntropy: 3.5},
+	{ID: "digitalocean_token", Provider: "digitalocean", Pattern: regexp.MustCompile(`\b(do[opr]_v1_[a-f0-9]{64})\b`)},
+
+	// Source control and package registries
+	{ID: "github_pat", Provider: "github", Pattern: regexp.MustCompile(`\b(ghp_[A-Za-z0-9]{36})\b`), Validate: validateCRC32Token, Checksum: true},
+	{ID: "github_oauth_token", Provider: "github", Pattern: regexp.MustCompile(`\b(gho_[A-Za-z0-9]{36})\b`), Validate: validateCRC32Token, Checksum: true},
+	{ID: "github_app_token", Provider: "github", Pattern: regexp.MustCompile(`\b((?:ghu|ghs|ghr)_[A-Za-z0-9]{36})\b`), Validate: validateCRC32Token, Checksum: true},
+	{ID: "github_pat", Provider: "github", Pattern: regexp.MustCompile(`\b(github_pat_[A-Za-z0-9]{22}_[A-Za-z0-9]{59})\b`)},
+	{ID: "gitlab_pat", Provider: "gitlab", Pattern: regexp.MustCompile(`\b(glpat-[A-Za-z0-9_-]{20})\b`)},
+	{ID: "npm_token", Provider: "npm", Pattern: regexp.MustCompile(`\b(npm_[A-Za-z0-9]{36})\b`), Validate: validateCRC32Token, Checksum: true},
+	{ID: "pypi_token", Provider: "pypi", Pattern: regexp.MustCompile(`\b(pypi-AgEIcHlwaS5vcmc[A-Za-z0-9_-]{50,})`), Validate: validatePyPIToken},
+
+	// AI providers
+	{ID: "anthropic_api_key", Provider: "anthropic", Pattern: regexp.MustCompile(`\b(sk-ant-(?:api|admin)\d{2}-[A-Za-z0-9_-]{80,}AA)\b`)},
+	{ID: "openai_api_key", Provider: "openai", Pattern: regexp.MustCompile(`\b(sk-(?:proj-|svcacct-|admin-)?[A-Za-z0-9_-]{20,}T3BlbkFJ[A-Za-z0-9_-]{20,})\b`)},
+	{ID: "huggingface_token", Provider: "huggingface", Pattern: regexp.MustCompile(`\b(hf_[A-Za-z]{34})\b`)},
+	{ID: "replicate_token", Provider: "replicate", Pattern: regexp.MustCompile(`\b(r8_[A-Za-z0-9]{37})\b`)},
+	{ID: "groq_api_key", Provider: "groq", Pattern: regexp.MustCompile(`\b(gsk_[A-Za-z0-9]{52})\b`)},
+	{ID: "perplexity_api_key", Provider: "perplexity", Pattern: regexp.MustCompile(`\b(pplx-[A-Za-z0-9]{48})\b`)},
+
+	// Payments and commerce
+	{ID: "stripe_secret_key", Provider: "stripe", Pattern: regexp.MustCompile(`\b((?:sk|rk)_live_[0-9A-Za-z]{24,99})\b`)},
+	{ID: "shopify_token", Provider: "shopify", Pattern: regexp.MustCompile(`\b(shp(?:at|ca|pa|ss)_[a-fA-F0-9]{32})\b`)},
+
+	// Messaging
+	{ID: "slack_token", Provider: "slack", Pattern: regexp.MustCompile(`\b(xox[abposr]-[0-9]{10,13}-[0-9A-Za-z-]{10,72})\b`)},
+	{ID: "slack_webhook", Provider: "slack", Pattern: regexp.MustCompile(`(https://hooks\.slack\.com/services/T[A-Z0-9]+/B[A-Z0-9]+/[A-Za-z0-9]{24})`)},
+	{ID: "discord_bot_token", Provider: "discord", Pattern: regexp.MustCompile(`\b([MNO][A-Za-z0-9_-]{23,25}\.[A-Za-z0-9_-]{6}\.[A-Za-z0-9_-]{27,38})\b`)},
+	{ID: "discord_webhook", Provider: "discord", Pattern: regexp.MustCompile(`(https://(?:ptb\.|canary\.)?discord(?:app)?\.com/api/webhooks/\d+/[A-Za-z0-9_-]{60,70})`)},
+	{ID: "telegram_bot_token", Provider: "telegram", Pattern: regexp.MustCompile(`\b(\d{8,10}:AA[0-9A-Za-z_-]{33})\b`)},
+	{ID: "twilio_api_key", Provider: "twilio", Pattern: regexp.MustCompile(`\b(SK[0-9a-f]{32})\b`), MinEntropy: 3.0},
+	{ID: "twilio_auth_token", Provider: "twilio", Pattern: regexp.MustCompile(`(?i)twilio\w*?_?(?:auth_?)?token["']?\s*[:=]\s*["']?([a-f0-9]{32})\b`)},
+	{ID: "sendgrid_api_key", Provider: "sendgrid", Pattern: regexp.MustCompile(`\b(SG\.[A-Za-z0-9_-]{22}\.[A-Za-z0-9_-]{43})\b`)},
+	{ID: "mailgun_api_key", Provider: "mailgun", Pattern: regexp.MustCompile(`\b(key-[0-9a-f]{32})\b`)},
+
+	// Generic credential formats
+	{ID: "private_key", Provider: "generic", Pattern: regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----`)},
+	{ID: "jwt", Provider: "generic", Pattern: regexp.MustCompile(`\b(eyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,})`), Validate: validateJWT},
+	{ID: "database_url", Provider: "generic", Pattern: regexp.MustCompile(`\b(?:postgres(?:ql)?|mysql|mariadb|mongodb(?:\+srv)?|redis|rediss|amqps?|mssql)://[^:/\s"'@]+:([^@/\s"']+)@[^\s"'/]+`), Validate: validateDatabasePassword},
+	{ID: "generic_key", Provider: "generic", Pattern: regexp.MustCompile(`["'](sk-[a-zA-Z0-9]{20,})["']`), MinEntropy: 3.5},
+}
+
+// genericAssignmentPattern finds credential-looking assignments for entropy analysis
+var genericAssignmentPattern = regexp.MustCompile(`(?i)\b[\w-]*(?:secret|token|api_?key|apikey|passw(?:or)?d|credential|auth_?key)[\w-]*["']?\s*[:=]\s*["']([^"'\s]{16,})["']`)
+
+// genericEntropyThreshold is the Shannon entropy (bits per char) a generic assignment needs to be reported
+const genericEntropyThreshold = 3.5
+
+// secretMatch is an in-memory detection; the raw value never leaves this package
+type secretMatch struct {
+	Rule      *secretRule
+	Value     string
+	Validated bool
+	Entropy   float64
+}
+
+// detectSecrets runs the provider catalogue and entropy analysis over a single line
+func detectSecrets(line string) []secretMatch {
+	matches := make([]secretMatch, 0)
+	seen := make(map[string]bool)
+
+	for _, rule := range secretRules {
+		for _, sub := range rule.Pattern.FindAllStringSubmatch(line, -1) {
+			value := sub[0]
+			if len(sub) > 1 && sub[1] != "" {
+				value = sub[1]
+			}
+			if seen[value] || isPlaceholderSecret(value) {
+				continue
+			}
+
+			entropy := shannonEntropy(value)
+			if rule.MinEntropy > 0 && entropy < rule.MinEntropy {
+				continue
+			}
+			validated := false
+			if rule.Validate != nil {
+				if !rule.Validate(value) {
+					continue
+				}
+				validated = rule.Checksum
+			}
+
+			seen[value] = true
+			matches = append(matches, secretMatch{Rule: rule, Value: value, Validated: validated, Entropy: entropy})
+		}
+	}
+
+	// Generic high-entropy assignments not already claimed by a provider rule
+	for _, sub := range genericAssignmentPattern.FindAllStringSubmatch(line, -1) {
+		value := sub[1]
+		if seen[value] || isPlaceholderSecret(value) || isReferenceValue(value) {
+			continue
+		}
+		entropy := shannonEntropy(value)
+		if entropy < genericEntropyThreshold {
+			continue
+		}
+		seen[value] = true
+		matches = append(matches, secretMatch{Rule: genericEntropyRule, Value: value, Entropy: entropy})
+	}
+
+	return matches
+}
+
+// genericEntropyRule labels matches found by entropy analysis
+var genericEntropyRule = &secretRule{ID: "high_entropy_string", Provider: "generic"}
+
+// findCommittedSecrets scans content for committed secrets
+func findCommittedSecrets(content, filePath string) []SecretFinding {
+	secrets := make([]SecretFinding, 0)
+	if isBinaryContent(content) {
+		return secrets
+	}
+
+	// Split into lines for line number tracking
+	lines := strings.Split(content, "\n")
+
+	for lineNum, line := range lines {
+		for _, match := range detectSecrets(line) {
+			secrets = append(secrets, match.finding(filePath, lineNum+1))
+		}
+	}
+
+	return secrets
+}
+
+// finding converts a match to a stored finding with a redacted snippet
+func (m secretMatch) finding(filePath string, lineNumber int) SecretFinding {
+	finding := SecretFinding{
+		FilePath:   filePath,
+		SecretType: m.Rule.ID,
+		Provider:   m.Rule.Provider,
+		LineNumber: lineNumber,
+		Snippet:    redactSecret(m.Value),
+		Validated:  m.Validated,
+	}
+	if m.Rule == genericEntropyRule {
+		finding.Entropy = math.Round(m.Entropy*100) / 100
+	}
+	if m.Rule.ID == "private_key" {
+		finding.Snippet = "Private key detected"
+	}
+	return finding
+}
+
+// redactSecret keeps at most a short prefix of a secret so findings can be recognised but never reused
+func redactSecret(secret string) string {
+	keep := len(secret) / 4
+	if keep > 8 {
+		keep = 8
+	}
+	if keep == 0 {
+		return "***REDACTED***"
+	}
+	return secret[:keep] + "***REDACTED***"
+}
+
+// shannonEntropy returns the Shannon entropy of a string in bits per character
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// placeholderMarkers appear in documentation values rather than real secrets
+var placeholderMarkers = []string{
+	"example", "xxxx", "****", "your", "placeholder", "changeme", "replace", "dummy",
+	"fake", "sample", "redacted", "insert", "<", ">", "...", "1234567890",
+}
+
+// isPlaceholderSecret reports whether a value is obviously not a real secret
+func isPlaceholderSecret(value string) bool {
+	lower := strings.ToLower(value)
+	for _, marker := range placeholderMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+
+	// Long runs of one character ("aaaaaaaa", "00000000")
+	run := 1
+	for i := 1; i < len(value); i++ {
+		if value[i] == value[i-1] {
+			run++
+			if run >= 8 {
+				return true
+			}
+		} else {
+			run = 1
+		}
+	}
+	return false
+}
+
+// isReferenceValue reports whether an assigned value refers to another variable instead of a literal
+func isReferenceValue(value string) bool {
+	return strings.Contains(value, "${") || strings.HasPrefix(value, "$") ||
+		strings.HasPrefix(value, "process.env") || strings.HasPrefix(value, "os.environ") ||
+		strings.Contains(value, "{{")
+}
+
+// isBinaryContent reports whether content looks like a binary file
+func isBinaryContent(content string) bool {
+	sample := content
+	if len(sample) > 8000 {
+		sample = sample[:8000]
+	}
+	return strings.IndexByte(sample, 0) >= 0
+}
+
+// base62Alphabet is the digit order GitHub and npm use for token checksums
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// validateCRC32Token verifies the CRC32 checksum embedded in GitHub and npm tokens:
+// prefix_ + 30 random chars + 6 char base62 CRC32 of the random part
+func validateCRC32Token(token string) bool {
+	idx := strings.IndexByte(token, '_')
+	if idx < 0 {
+		return false
+	}
+	body := token[idx+1:]
+	if len(body) != 36 {
+		return false
+	}
+	random, checksum := body[:30], body[30:]
+	return encodeBase62(uint64(crc32.ChecksumIEEE([]byte(random))), 6) == checksum
+}
+
+// encodeBase62 encodes n in base62, left-padded with zeros to width
+func encodeBase62(n uint64, width int) string {
+	buf := make([]byte, 0, width)
+	for n > 0 {
+		buf = append(buf, base62Alphabet[n%62])
+		n /= 62
+	}
+	for len(buf) < width {
+		buf = append(buf, '0')
+	}
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+	return string(buf)
+}
+
+// validateBase64Length returns a validator that checks a value decodes to n bytes
+func validateBase64Length(n int) func(string) bool {
+	return func(value string) bool {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		return err == nil && len(decoded) == n
+	}
+}
+
+// validatePyPIToken checks that a PyPI token is a macaroon issued by pypi.org
+func validatePyPIToken(token string) bool {
+	body := strings.TrimPrefix(token, "pypi-")
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(body, "="))
+	if err != nil {
+		// Trailing characters may not align to a full quantum; the prefix is what matters
+		decoded, _ = base64.RawURLEncoding.DecodeString(body[:len(body)/4*4])
+	}
+	return strings.Contains(string(decoded), "pypi.org")
+}
+
+// validateJWT checks that a token has a JSON header declaring an algorithm
+func validateJWT(token string) bool {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return false
+	}
+	var h struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(header, &h); err != nil {
+		return false
+	}
+	// Unsigned tokens carry no credential
+	return h.Alg != "" && !strings.EqualFold(h.Alg, "none")
+}
+
+// defaultDatabasePasswords are development defaults that aren't worth reporting
+var defaultDatabasePasswords = map[string]bool{
+	"password": true, "postgres": true, "root": true, "admin": true, "secret": true,
+	"pass": true, "test": true, "user": true, "mysql": true, "guest": true, "mcpsek": true,
+}
+
+// validateDatabasePassword discards connection strings with template or default passwords
+func validateDatabasePassword(password string) bool {
+	if isReferenceValue(password) || strings.HasPrefix(password, "%") {
+		return false
+	}
+	return !defaultDatabasePasswords[strings.ToLower(password)]
+}
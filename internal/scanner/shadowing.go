@@ -0,0 +1,254 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mcpsek/mcpsek/internal/database"
+)
+
+// ToolCollision is a tool that shadows or references another server's tool
+type ToolCollision struct {
+	Kind       string          `json:"kind"`     // "name_collision" or "cross_reference"
+	Severity   string          `json:"severity"` // "warning" or "critical"
+	ServerID   uuid.UUID       `json:"server_id"`
+	ServerName string          `json:"server_name"`
+	ToolName   string          `json:"tool_name"`
+	Target     CollisionTarget `json:"target"`
+	Message    string          `json:"message"`
+	Snippet    string          `json:"snippet,omitempty"`
+
+	// Context of the file defining the tool; unset for collisions across the whole index
+	FileContext
+}
+
+// CollisionTarget is the popular server's tool that is shadowed or referenced
+type CollisionTarget struct {
+	ServerID   uuid.UUID `json:"server_id"`
+	ServerName string    `json:"server_name"`
+	Stars      int       `json:"stars"`
+	ToolName   string    `json:"tool_name"`
+}
+
+// PopularServerStars is how many stars make a server's tool names worth protecting
+const PopularServerStars = 100
+
+// Patterns for shadowing
+var (
+	// Multi-word tool names; single words like "search" collide by accident
+	distinctiveToolNamePattern = regexp.MustCompile(`^[A-Za-z0-9]+(?:[_\-.][A-Za-z0-9]+)+$|^[a-z0-9]+(?:[A-Z][a-z0-9]*)+$`)
+
+	// Identifiers in a description
+	descriptionIdentifierPattern = regexp.MustCompile(`[A-Za-z0-9]+(?:[_\-.][A-Za-z0-9]+)*`)
+
+	// Wording that tells the model to prefer this tool over another
+	overrideWordingPattern = regexp.MustCompile(`(?i)\binstead of\b|\boverrides?\b|\breplaces?\b|\bsupersedes?\b|\brather than\b|\b(?:do not|don't|never) (?:use|call)\b|\bdeprecated\b|\bbroken\b|\balways use this\b|\buse this (?:tool )?instead\b|\bbefore (?:using|calling)\b`)
+)
+
+// normalizeToolName folds case and separators so read-file, readFile and read_file collide
+func normalizeToolName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '-' || r == '.':
+			b.WriteByte('_')
+		case r >= 'A' && r <= 'Z':
+			if i > 0 && name[i-1] >= 'a' && name[i-1] <= 'z' {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r + ('a' - 'A'))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// collisionIndex is the tool-name index: popular owners of each distinctive name, most stars
+// first, and every server's own tool names
+type collisionIndex struct {
+	owners   map[string][]*database.IndexedTool
+	ownTools map[uuid.UUID]map[string]bool
+}
+
+// newCollisionIndex builds the tool-name index
+func newCollisionIndex(index []*database.IndexedTool) *collisionIndex {
+	ci := &collisionIndex{
+		owners:   make(map[string][]*database.IndexedTool),
+		ownTools: make(map[uuid.UUID]map[string]bool),
+	}
+	for _, tool := range index {
+		name := normalizeToolName(tool.ToolName)
+		if ci.ownTools[tool.ServerID] == nil {
+			ci.ownTools[tool.ServerID] = make(map[string]bool)
+		}
+		ci.ownTools[tool.ServerID][name] = true
+
+		if tool.Stars >= PopularServerStars && distinctiveToolNamePattern.MatchString(tool.ToolName) {
+			ci.owners[name] = append(ci.owners[name], tool)
+		}
+	}
+	for _, list := range ci.owners {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Stars > list[j].Stars })
+	}
+	return ci
+}
+
+// FindCollisions builds the tool-name index and returns tools colliding with popular servers'
+// tool names, and descriptions that reference other servers' tools
+func FindCollisions(index []*database.IndexedTool) []ToolCollision {
+	ci := newCollisionIndex(index)
+	collisions := make([]ToolCollision, 0)
+	for _, tool := range index {
+		collisions = append(collisions, ci.check(tool)...)
+	}
+	return collisions
+}
+
+// check returns one tool's collisions with the index
+func (ci *collisionIndex) check(tool *database.IndexedTool) []ToolCollision {
+	collisions := make([]ToolCollision, 0)
+	name := normalizeToolName(tool.ToolName)
+
+	// Same name as a more popular server's tool
+	for _, owner := range ci.owners[name] {
+		if owner.ServerID == tool.ServerID || owner.Stars <= tool.Stars {
+			continue
+		}
+		collisions = append(collisions, ToolCollision{
+			Kind:       "name_collision",
+			Severity:   "warning",
+			ServerID:   tool.ServerID,
+			ServerName: tool.ServerName,
+			ToolName:   tool.ToolName,
+			Target:     collisionTarget(owner),
+			Message:    fmt.Sprintf("Tool name collides with %s's %s; clients with both installed may call either", owner.ServerName, owner.ToolName),
+		})
+		break
+	}
+
+	// Description names another server's tool
+	if tool.Description == nil {
+		return collisions
+	}
+	desc := *tool.Description
+	referenced := make(map[string]bool)
+	for _, loc := range descriptionIdentifierPattern.FindAllStringIndex(desc, -1) {
+		word := desc[loc[0]:loc[1]]
+		ref := normalizeToolName(word)
+		if referenced[ref] || ci.ownTools[tool.ServerID][ref] || !distinctiveToolNamePattern.MatchString(word) {
+			continue
+		}
+		list := ci.owners[ref]
+		if len(list) == 0 {
+			continue
+		}
+		referenced[ref] = true
+
+		owner := list[0]
+		severity := "warning"
+		message := fmt.Sprintf("Description references %s's %s tool", owner.ServerName, owner.ToolName)
+		// Only wording about the referenced tool counts, not a "deprecated" elsewhere in the description
+		if overrideWordingPattern.MatchString(clauseAround(desc, loc[0], loc[1])) {
+			severity = "critical"
+			message = fmt.Sprintf("Description tells the model to prefer this tool over %s's %s", owner.ServerName, owner.ToolName)
+		}
+		collisions = append(collisions, ToolCollision{
+			Kind:       "cross_reference",
+			Severity:   severity,
+			ServerID:   tool.ServerID,
+			ServerName: tool.ServerName,
+			ToolName:   tool.ToolName,
+			Target:     collisionTarget(owner),
+			Message:    message,
+			Snippet:    truncate(snippetAround(desc, loc[0]), 200),
+		})
+	}
+
+	return collisions
+}
+
+// FindShadowing returns the collisions for one server's freshly scanned tools, each with the
+// context of the file that defines the tool
+func FindShadowing(server *database.Server, tools []*ToolDefinition, index []*database.IndexedTool) []ToolCollision {
+	// Replace the server's stored tools with the ones from this scan
+	current := make([]*database.IndexedTool, 0, len(index)+len(tools))
+	for _, tool := range index {
+		if tool.ServerID != server.ID {
+			current = append(current, tool)
+		}
+	}
+	scanned := make([]*database.IndexedTool, len(tools))
+	for i, tool := range tools {
+		scanned[i] = &database.IndexedTool{
+			ServerID:    server.ID,
+			ServerName:  server.Name,
+			Stars:       server.Stars,
+			ToolName:    tool.Name,
+			Description: strPtr(tool.Description),
+		}
+	}
+	ci := newCollisionIndex(append(current, scanned...))
+
+	collisions := make([]ToolCollision, 0)
+	for i, tool := range tools {
+		for _, c := range ci.check(scanned[i]) {
+			c.FileContext = tool.FileContext
+			collisions = append(collisions, c)
+		}
+	}
+	return collisions
+}
+
+// AddShadowing records collisions with other servers' tools and escalates the status for tools
+// in trusted locations
+func (r *IntegrityResult) AddShadowing(rc *RepoContext, collisions []ToolCollision) {
+	r.Shadowing = collisions
+	for _, c := range collisions {
+		if rc.Counts(c.FileContext) {
+			r.Status = escalateStatus(r.Status, c.Severity)
+		}
+	}
+}
+
+// collisionTarget describes an indexed tool as a collision target
+func collisionTarget(tool *database.IndexedTool) CollisionTarget {
+	return CollisionTarget{
+		ServerID:   tool.ServerID,
+		ServerName: tool.ServerName,
+		Stars:      tool.Stars,
+		ToolName:   tool.ToolName,
+	}
+}
+
+// snippetAround returns the text around offset, trimmed to whole words
+func snippetAround(text string, offset int) string {
+	start := max(0, offset-80)
+	end := min(len(text), offset+120)
+	if i := strings.IndexByte(text[start:offset], ' '); start > 0 && i >= 0 {
+		start += i + 1
+	}
+	if i := strings.LastIndexByte(text[offset:end], ' '); end < len(text) && i > 0 {
+		end = offset + i
+	}
+	return strings.TrimSpace(text[start:end])
+}
+
+// clauseAround returns the sentence or clause of text containing text[start:end]
+func clauseAround(text string, start, end int) string {
+	from := strings.LastIndexAny(text[:start], ";!?\n")
+	if i := strings.LastIndex(text[:start], ". "); i > from {
+		from = i
+	}
+	to := len(text)
+	if i := strings.IndexAny(text[end:], ";!?\n"); i >= 0 {
+		to = end + i
+	}
+	if i := strings.Index(text[end:], ". "); i >= 0 && end+i < to {
+		to = end + i
+	}
+	return text[from+1 : to]
+}
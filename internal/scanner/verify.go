@@ -0,0 +1,190 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mcpsek/mcpsek/internal/database"
+)
+
+// Tool verification statuses
+const (
+	VerifyMatched = "matched" // Same name and hash as the latest scan
+	VerifyMutated = "mutated" // Name was scanned, but the definition differs
+	VerifyUnknown = "unknown" // Name was never scanned
+)
+
+// ObservedTool is a tool as a client received it from tools/list
+type ObservedTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema,omitempty"`
+}
+
+// ToolVerification is the verdict for one observed tool
+type ToolVerification struct {
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	ObservedHash string `json:"observed_hash"`
+	ExpectedHash string `json:"expected_hash,omitempty"` // Hash in the latest scan, for matched and mutated tools
+	// When a mutated tool's definition matches an older scan, the last time it was seen
+	PreviouslySeen *time.Time `json:"previously_seen,omitempty"`
+}
+
+// HashTool hashes an observed tool the way scanned tool definitions are hashed
+func HashTool(name, description string) string {
+	return computeHash(name, normalizeDescription(description))
+}
+
+// ParseToolsList reads the tools from a tools/list payload: the tools array, the result object
+// or the whole JSON-RPC response
+func ParseToolsList(raw json.RawMessage) ([]ObservedTool, error) {
+	var tools []ObservedTool
+	if err := json.Unmarshal(raw, &tools); err == nil {
+		return tools, nil
+	}
+
+	var payload struct {
+		Tools  []ObservedTool `json:"tools"`
+		Result *struct {
+			Tools []ObservedTool `json:"tools"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("parse tools/list payload: %w", err)
+	}
+	if payload.Result != nil {
+		return payload.Result.Tools, nil
+	}
+	if payload.Tools == nil {
+		return nil, fmt.Errorf("parse tools/list payload: no tools array")
+	}
+	return payload.Tools, nil
+}
+
+// VerifyTools compares observed tools with the latest scan's tools
+// known holds every definition ever stored for the server, to recognise reverted or stale tools;
+// it returns a verdict per observed tool and the names of scanned tools the client didn't see
+func VerifyTools(observed []ObservedTool, current, known []*database.ToolDefinition) ([]ToolVerification, []string) {
+	currentByName := make(map[string]*database.ToolDefinition)
+	for _, tool := range current {
+		currentByName[tool.ToolName] = tool
+	}
+	lastSeen := make(map[string]time.Time)
+	for _, tool := range known {
+		if tool.LastSeen.After(lastSeen[tool.ContentHash]) {
+			lastSeen[tool.ContentHash] = tool.LastSeen
+		}
+	}
+
+	results := make([]ToolVerification, 0, len(observed))
+	seen := make(map[string]bool)
+	for _, tool := range observed {
+		seen[tool.Name] = true
+		result := ToolVerification{Name: tool.Name, ObservedHash: HashTool(tool.Name, tool.Description)}
+
+		scanned, ok := currentByName[tool.Name]
+		switch {
+		case !ok:
+			result.Status = VerifyUnknown
+		case scanned.ContentHash == result.ObservedHash:
+			result.Status = VerifyMatched
+			result.ExpectedHash = scanned.ContentHash
+		default:
+			result.Status = VerifyMutated
+			result.ExpectedHash = scanned.ContentHash
+			if t, ok := lastSeen[result.ObservedHash]; ok {
+				result.PreviouslySeen = &t
+			}
+		}
+		results = append(results, result)
+	}
+
+	missing := make([]string, 0)
+	for name := range currentByName {
+		if !seen[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+
+	return results, missing
+}
+
+// ClientConfigServer is one server of an MCP client config, with the package it runs if recognised
+type ClientConfigServer struct {
+	Name     string `json:"name"`
+	Registry string `json:"registry,omitempty"` // "npm" or "pypi"
+	Package  string `json:"package,omitempty"`
+	URL      string `json:"url,omitempty"` // Remote servers
+}
+
+// packageVersionPattern matches a version suffix on a package argument
+var packageVersionPattern = regexp.MustCompile(`^(@?[^@=<>~!\s]+)(?:@|==|>=|~=).*$`)
+
+// ParseClientConfig reads the servers of an MCP client config (Claude Desktop, Cursor, VS Code, Zed)
+func ParseClientConfig(raw []byte) ([]ClientConfigServer, error) {
+	entries := parseClientConfig(configBlock{lang: "json", body: string(raw)})
+	if entries == nil {
+		return nil, fmt.Errorf("no MCP servers found in client config")
+	}
+
+	servers := make([]ClientConfigServer, 0, len(entries))
+	for name, entry := range entries {
+		server := ClientConfigServer{Name: name, URL: entry.URL}
+		server.Registry, server.Package = packageFromCommand(entry.Command, entry.Args)
+		servers = append(servers, server)
+	}
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].Name < servers[j].Name
+	})
+
+	return servers, nil
+}
+
+// packageFromCommand infers the registry and package a client launches a server with
+func packageFromCommand(command string, args []string) (string, string) {
+	registry := ""
+	switch strings.TrimSuffix(filepath.Base(command), ".cmd") {
+	case "npx", "bunx", "pnpx":
+		registry = "npm"
+	case "uvx", "pipx":
+		registry = "pypi"
+		if len(args) > 0 && args[0] == "run" {
+			args = args[1:]
+		}
+	default:
+		return "", ""
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-p" || arg == "--package" || arg == "--from" || arg == "--spec":
+			// The package is given explicitly; the next positional is the binary
+			if i+1 < len(args) {
+				return registry, stripPackageVersion(args[i+1])
+			}
+			return "", ""
+		case strings.HasPrefix(arg, "-"):
+			continue
+		default:
+			return registry, stripPackageVersion(arg)
+		}
+	}
+
+	return "", ""
+}
+
+// stripPackageVersion removes a version suffix such as @1.2.3 or ==1.2.3
+func stripPackageVersion(arg string) string {
+	if m := packageVersionPattern.FindStringSubmatch(arg); m != nil {
+		return m[1]
+	}
+	return arg
+}
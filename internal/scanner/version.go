@@ -0,0 +1,85 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Revision identifies what a scan analysed
+type Revision struct {
+	CommitSHA      string `json:"commit_sha"`
+	Branch         string `json:"branch,omitempty"`          // The repository's default branch
+	Ref            string `json:"ref,omitempty"`             // Tag, branch or commit requested for an on-demand scan
+	PackageVersion string `json:"package_version,omitempty"` // Version in the package manifest at this commit
+}
+
+// setupVersionPattern matches version="1.2.3" in a setup.py
+var setupVersionPattern = regexp.MustCompile(`\bversion\s*=\s*["']([^"']+)["']`)
+
+// VersionRefs returns the tags a published version is commonly released under
+func VersionRefs(version, packageName string) []string {
+	version = strings.TrimPrefix(version, "v")
+	refs := []string{"v" + version, version}
+	if packageName != "" {
+		refs = append(refs, packageName+"@"+version)
+	}
+	return refs
+}
+
+// detectPackageVersion reads the package version from the manifest at the repository root,
+// trying package.json, pyproject.toml and setup.py in turn
+func detectPackageVersion(repoPath string) string {
+	if data, err := os.ReadFile(filepath.Join(repoPath, "package.json")); err == nil {
+		var pkg struct {
+			Version string `json:"version"`
+		}
+		if json.Unmarshal(data, &pkg) == nil && pkg.Version != "" {
+			return pkg.Version
+		}
+	}
+
+	if version := pyprojectVersion(filepath.Join(repoPath, "pyproject.toml")); version != "" {
+		return version
+	}
+
+	if data, err := os.ReadFile(filepath.Join(repoPath, "setup.py")); err == nil {
+		if m := setupVersionPattern.FindSubmatch(data); m != nil {
+			return string(m[1])
+		}
+	}
+
+	return ""
+}
+
+// pyprojectVersion reads the static version from a pyproject.toml's [project] or [tool.poetry] table
+func pyprojectVersion(p string) string {
+	f, err := os.Open(p)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	inPackage := false
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "[") {
+			section := strings.Trim(line, "[] ")
+			inPackage = section == "project" || section == "tool.poetry"
+			continue
+		}
+		if !inPackage {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) == 2 && strings.TrimSpace(parts[0]) == "version" {
+			return strings.Trim(strings.TrimSpace(parts[1]), `"'`)
+		}
+	}
+	return ""
+}
@@ -9,28 +9,32 @@ import (
 	"github.com/mcpsek/mcpsek/internal/database"
 	"github.com/mcpsek/mcpsek/internal/discovery"
 	"github.com/mcpsek/mcpsek/internal/scanner"
+	"github.com/mcpsek/mcpsek/internal/shodan"
 )
 
 // Scheduler manages discovery and scanning
 type Scheduler struct {
-	db                *database.DB
-	scanner           *scanner.Scanner
-	npmDiscoverer     *discovery.NPMDiscoverer
-	githubDiscoverer  *discovery.GitHubDiscoverer
+	db                 *database.DB
+	scanner            *scanner.Scanner
+	npmDiscoverer      *discovery.NPMDiscoverer
+	githubDiscoverer   *discovery.GitHubDiscoverer
 	registryDiscoverer *discovery.RegistryDiscoverer
-	workerCount       int
-	scanInterval      time.Duration
-	discoveryInterval time.Duration
+	observer           *shodan.Observer // nil when no Shodan key is configured
+	workerCount        int
+	scanInterval       time.Duration
+	discoveryInterval  time.Duration
 }
 
 // New creates a new scheduler
-func New(db *database.DB, scn *scanner.Scanner, githubToken string, workerCount int, scanInterval, discoveryInterval time.Duration) *Scheduler {
+// observer may be nil to skip observed exposure lookups
+func New(db *database.DB, scn *scanner.Scanner, observer *shodan.Observer, githubToken string, workerCount int, scanInterval, discoveryInterval time.Duration) *Scheduler {
 	return &Scheduler{
 		db:                 db,
 		scanner:            scn,
 		npmDiscoverer:      discovery.NewNPMDiscoverer(),
 		githubDiscoverer:   discovery.NewGitHubDiscoverer(githubToken),
 		registryDiscoverer: discovery.NewRegistryDiscoverer(),
+		observer:           observer,
 		workerCount:        workerCount,
 		scanInterval:       scanInterval,
 		discoveryInterval:  discoveryInterval,
@@ -214,14 +218,27 @@ func (s *Scheduler) scanServer(ctx context.Context, server *database.Server) err
 		return err
 	}
 
+	// Claim any queued rescan now, so one queued while this scan runs isn't lost
+	if err := s.db.DequeueScan(ctx, server.ID); err != nil {
+		log.Printf("Dequeue failed for %s: %v", server.Name, err)
+	}
+
 	// Perform scan
-	_, err := s.scanner.Scan(ctx, server.ID, server.SourceURL)
+	result, err := s.scanner.Scan(ctx, server.ID, server.SourceURL)
 	if err != nil {
 		errMsg := err.Error()
 		s.db.UpdateServerScanStatus(ctx, server.ID, "failed", &errMsg)
 		return err
 	}
 
+	// Look for live instances of network servers; failures don't fail the scan
+	if s.observer != nil && result.ExposureResult.IsNetwork() {
+		exposure := result.ExposureResult
+		if err := s.observer.Observe(ctx, server, exposure.DefaultPort, exposure.Transports); err != nil {
+			log.Printf("Observed exposure lookup failed for %s: %v", server.Name, err)
+		}
+	}
+
 	// Status updated by scanner
 	return nil
 }
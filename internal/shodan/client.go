@@ -0,0 +1,148 @@
+package shodan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBaseURL is the public Shodan API
+const DefaultBaseURL = "https://api.shodan.io"
+
+// Client queries the Shodan host search API, or a compatible stand-in
+type Client struct {
+	client  *http.Client
+	apiKey  string
+	baseURL string
+	limiter *rateLimiter
+}
+
+// SearchResult is the subset of a host search response mcpsek uses
+type SearchResult struct {
+	Total   int     `json:"total"`
+	Matches []Match `json:"matches"`
+}
+
+// Match is one service banner returned by a host search
+type Match struct {
+	IP        string   `json:"ip_str"`
+	Port      int      `json:"port"`
+	Hostnames []string `json:"hostnames"`
+	Org       string   `json:"org"`
+	Timestamp string   `json:"timestamp"` // e.g. "2024-05-01T12:00:00.123456"
+	Data      string   `json:"data"`      // Raw banner, including HTTP response headers
+	Location  struct {
+		CountryCode string `json:"country_code"`
+	} `json:"location"`
+	HTTP *struct {
+		Title string `json:"title"`
+		HTML  string `json:"html"`
+	} `json:"http,omitempty"`
+	SSL json.RawMessage `json:"ssl,omitempty"`
+}
+
+// NewClient creates a new Shodan client allowing requestsPerMinute API calls
+func NewClient(apiKey, baseURL string, requestsPerMinute int) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if requestsPerMinute <= 0 {
+		requestsPerMinute = 60
+	}
+	return &Client{
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		limiter: &rateLimiter{interval: time.Minute / time.Duration(requestsPerMinute)},
+	}
+}
+
+// Search runs a host search query
+func (c *Client) Search(ctx context.Context, query string) (*SearchResult, error) {
+	if err := c.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("key", c.apiKey)
+	params.Set("query", query)
+	searchURL := c.baseURL + "/shodan/host/search?" + params.Encode()
+
+	// Errors from here on would quote the URL, API key included, so only their cause is returned
+	req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build shodan request: %w", withoutURL(err))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("shodan search: %w", withoutURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("shodan search returned status %d", resp.StatusCode)
+	}
+
+	var result SearchResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode shodan response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// withoutURL unwraps a *url.Error to its cause, dropping the URL from the message
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// LastSeen parses the match timestamp, which Shodan sends without a zone
+func (m *Match) LastSeen() time.Time {
+	for _, layout := range []string{"2006-01-02T15:04:05.999999", time.RFC3339Nano} {
+		if t, err := time.Parse(layout, m.Timestamp); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// rateLimiter spaces requests at least interval apart across goroutines
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// wait blocks until the next request slot
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(slot))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,179 @@
+package shodan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/mcpsek/mcpsek/internal/database"
+)
+
+// cacheSource identifies Shodan responses in the exposure cache
+const cacheSource = "shodan"
+
+// MCP response signatures per transport, as they appear in HTTP banners
+var transportSignatures = map[string]string{
+	"streamable_http": "mcp-session-id",
+	"sse":             "event: endpoint",
+	"websocket":       "mcp-session-id",
+	"http":            "mcp-session-id",
+}
+
+// Observer links internet-exposed MCP instances to server records
+type Observer struct {
+	client   *Client
+	db       *database.DB
+	cacheTTL time.Duration
+}
+
+// NewObserver creates a new observer caching responses for cacheTTL
+func NewObserver(client *Client, db *database.DB, cacheTTL time.Duration) *Observer {
+	return &Observer{
+		client:   client,
+		db:       db,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// Observe looks up live instances of a network-transport server and stores them on the server record
+// Hosts are found by the server's default port and MCP signatures, and linked when their banner
+// names the server's package or repository
+func (o *Observer) Observe(ctx context.Context, server *database.Server, defaultPort *int, transports []string) error {
+	identifiers := serverIdentifiers(server)
+
+	observed := &database.ObservedExposure{
+		Source:    cacheSource,
+		CheckedAt: time.Now(),
+		Queries:   make([]string, 0),
+		Instances: make([]database.ObservedInstance, 0),
+	}
+	seen := make(map[string]bool)
+
+	for _, signature := range signaturesFor(transports) {
+		queries := make([]string, 0, 2)
+		if defaultPort != nil {
+			queries = append(queries, fmt.Sprintf(`port:%d "%s"`, *defaultPort, signature))
+		}
+		if len(identifiers) > 0 {
+			queries = append(queries, fmt.Sprintf(`"%s" "%s"`, signature, identifiers[0]))
+		}
+
+		for i, query := range queries {
+			result, err := o.search(ctx, query)
+			if err != nil {
+				return fmt.Errorf("shodan query %q: %w", query, err)
+			}
+			observed.Queries = append(observed.Queries, query)
+			// The port query measures how widespread the signature is on this port
+			if i == 0 && defaultPort != nil {
+				observed.TotalMatches += result.Total
+			}
+
+			for _, match := range result.Matches {
+				key := fmt.Sprintf("%s:%d", match.IP, match.Port)
+				linkedBy := matchIdentifier(&match, identifiers)
+				if linkedBy == "" || seen[key] {
+					continue
+				}
+				seen[key] = true
+				observed.Instances = append(observed.Instances, database.ObservedInstance{
+					IP:        match.IP,
+					Port:      match.Port,
+					Hostnames: match.Hostnames,
+					Org:       match.Org,
+					Country:   match.Location.CountryCode,
+					TLS:       len(match.SSL) > 0 && string(match.SSL) != "null",
+					Signature: signature,
+					LinkedBy:  linkedBy,
+					LastSeen:  match.LastSeen(),
+				})
+			}
+		}
+	}
+
+	return o.db.UpdateObservedExposure(ctx, server.ID, observed)
+}
+
+// search returns a cached response when fresh, otherwise queries Shodan and caches the result
+func (o *Observer) search(ctx context.Context, query string) (*SearchResult, error) {
+	cached, ok, err := o.db.GetExposureCache(ctx, cacheSource, query, o.cacheTTL)
+	if err != nil {
+		log.Printf("Exposure cache lookup failed: %v", err)
+	}
+	if ok {
+		var result SearchResult
+		if err := json.Unmarshal(cached, &result); err == nil {
+			return &result, nil
+		}
+	}
+
+	result, err := o.client.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(result); err == nil {
+		if err := o.db.PutExposureCache(ctx, cacheSource, query, data); err != nil {
+			log.Printf("Exposure cache store failed: %v", err)
+		}
+	}
+
+	return result, nil
+}
+
+// signaturesFor returns the distinct signatures of the network transports
+func signaturesFor(transports []string) []string {
+	signatures := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, t := range transports {
+		sig, ok := transportSignatures[t]
+		if !ok || seen[sig] {
+			continue
+		}
+		seen[sig] = true
+		signatures = append(signatures, sig)
+	}
+	return signatures
+}
+
+// serverIdentifiers returns names a live instance may report: package name first, then repository name
+// Short names are dropped since they match unrelated banners
+func serverIdentifiers(server *database.Server) []string {
+	candidates := make([]string, 0, 3)
+	if server.PackageName != nil {
+		candidates = append(candidates, *server.PackageName)
+	}
+	candidates = append(candidates, path.Base(strings.TrimSuffix(server.SourceURL, ".git")), server.Name)
+
+	identifiers := make([]string, 0, len(candidates))
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		c = strings.ToLower(strings.TrimSpace(c))
+		if len(c) < 5 || seen[c] {
+			continue
+		}
+		seen[c] = true
+		identifiers = append(identifiers, c)
+	}
+	return identifiers
+}
+
+// matchIdentifier returns the first identifier found in the match banner or page
+func matchIdentifier(match *Match, identifiers []string) string {
+	text := match.Data
+	if match.HTTP != nil {
+		text += "\n" + match.HTTP.Title + "\n" + match.HTTP.HTML
+	}
+	text = strings.ToLower(text)
+
+	for _, id := range identifiers {
+		if strings.Contains(text, id) {
+			return id
+		}
+	}
+	return ""
+}
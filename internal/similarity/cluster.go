@@ -0,0 +1,68 @@
+package similarity
+
+// DefaultThreshold is the estimated Jaccard similarity that makes two descriptions near-duplicates;
+// a payload with a handful of reworded phrases stays above it, a paraphrase of a common tool doesn't
+const DefaultThreshold = 0.5
+
+// Cluster groups signatures into near-duplicate clusters using LSH candidates, keeping pairs at or
+// above threshold; it returns the indices of each cluster with more than one member
+func Cluster(signatures []Signature, threshold float64) [][]int {
+	parent := make([]int, len(signatures))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	// Candidates share a bucket in at least one band
+	type bandBucket struct {
+		band   int
+		bucket uint64
+	}
+	buckets := make(map[bandBucket][]int)
+	for i, sig := range signatures {
+		for band, bucket := range sig.Buckets() {
+			key := bandBucket{band, bucket}
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+
+	checked := make(map[[2]int]bool)
+	for _, members := range buckets {
+		for a := 0; a < len(members); a++ {
+			for b := a + 1; b < len(members); b++ {
+				pair := [2]int{members[a], members[b]}
+				if checked[pair] {
+					continue
+				}
+				checked[pair] = true
+				if signatures[pair[0]].Similarity(signatures[pair[1]]) >= threshold {
+					parent[find(pair[0])] = find(pair[1])
+				}
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	order := make([]int, 0)
+	for i := range signatures {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			order = append(order, root)
+		}
+		groups[root] = append(groups[root], i)
+	}
+
+	clusters := make([][]int, 0)
+	for _, root := range order {
+		if len(groups[root]) > 1 {
+			clusters = append(clusters, groups[root])
+		}
+	}
+	return clusters
+}
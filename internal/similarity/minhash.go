@@ -0,0 +1,151 @@
+package similarity
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"regexp"
+	"strings"
+)
+
+// MinHash parameters; Bands * Rows must equal NumHashes
+const (
+	NumHashes   = 128
+	Bands       = 32
+	Rows        = 4
+	ShingleSize = 5 // Characters per shingle; word shingles lose too much to small edits
+
+	// MinShingles is how many shingles a description needs to be compared at all;
+	// shorter ones are near-duplicates of each other by accident
+	MinShingles = 36
+)
+
+// Signature is a MinHash signature of a text's shingles
+type Signature []uint64
+
+// nonWordPattern matches everything that isn't part of a word
+var nonWordPattern = regexp.MustCompile(`[^\p{L}\p{N}<>/~._-]+`)
+
+// hashSeeds are the per-function multipliers and offsets, fixed so stored signatures stay comparable
+var hashSeeds = func() [NumHashes][2]uint64 {
+	var seeds [NumHashes][2]uint64
+	state := uint64(0x6d637073656b) // "mcpsek"
+	next := func() uint64 {
+		// splitmix64
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		return z ^ (z >> 31)
+	}
+	for i := range seeds {
+		seeds[i] = [2]uint64{next() | 1, next()}
+	}
+	return seeds
+}()
+
+// Normalize lowercases text and collapses punctuation and whitespace, so edits to formatting
+// don't change the shingles
+func Normalize(text string) string {
+	return strings.TrimSpace(nonWordPattern.ReplaceAllString(strings.ToLower(text), " "))
+}
+
+// Shingles returns the distinct character shingles of normalized text
+func Shingles(text string) []string {
+	runes := []rune(Normalize(text))
+	if len(runes) < ShingleSize {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	shingles := make([]string, 0, len(runes)-ShingleSize+1)
+	for i := 0; i+ShingleSize <= len(runes); i++ {
+		shingle := string(runes[i : i+ShingleSize])
+		if !seen[shingle] {
+			seen[shingle] = true
+			shingles = append(shingles, shingle)
+		}
+	}
+	return shingles
+}
+
+// Compute returns the MinHash signature of text, or nil when it has too few shingles
+func Compute(text string) Signature {
+	shingles := Shingles(text)
+	if len(shingles) < MinShingles {
+		return nil
+	}
+
+	sig := make(Signature, NumHashes)
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+	for _, shingle := range shingles {
+		h := fnv.New64a()
+		h.Write([]byte(shingle))
+		x := h.Sum64()
+		for i, seed := range hashSeeds {
+			v := x*seed[0] + seed[1]
+			v ^= v >> 33
+			if v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+	return sig
+}
+
+// Similarity estimates the Jaccard similarity of the texts behind two signatures
+func (s Signature) Similarity(other Signature) float64 {
+	if len(s) != NumHashes || len(other) != NumHashes {
+		return 0
+	}
+	equal := 0
+	for i := range s {
+		if s[i] == other[i] {
+			equal++
+		}
+	}
+	return float64(equal) / NumHashes
+}
+
+// Buckets returns one LSH bucket per band; signatures sharing any bucket are candidates
+func (s Signature) Buckets() []uint64 {
+	if len(s) != NumHashes {
+		return nil
+	}
+	buckets := make([]uint64, Bands)
+	buf := make([]byte, 8*Rows)
+	for band := 0; band < Bands; band++ {
+		for row := 0; row < Rows; row++ {
+			binary.LittleEndian.PutUint64(buf[row*8:], s[band*Rows+row])
+		}
+		h := fnv.New64a()
+		h.Write(buf)
+		buckets[band] = h.Sum64()
+	}
+	return buckets
+}
+
+// Int64s converts the signature for storage in a BIGINT[] column
+func (s Signature) Int64s() []int64 {
+	if s == nil {
+		return nil
+	}
+	values := make([]int64, len(s))
+	for i, v := range s {
+		values[i] = int64(v)
+	}
+	return values
+}
+
+// FromInt64s converts a stored signature back
+func FromInt64s(values []int64) Signature {
+	if len(values) == 0 {
+		return nil
+	}
+	sig := make(Signature, len(values))
+	for i, v := range values {
+		sig[i] = uint64(v)
+	}
+	return sig
+}
@@ -1,6 +1,7 @@
 package web
 
 import (
+	"encoding/json"
 	"html/template"
 	"net/http"
 	"path/filepath"
@@ -8,6 +9,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/mcpsek/mcpsek/internal/database"
+	"github.com/mcpsek/mcpsek/internal/scanner"
 )
 
 // Web handles web UI requests
@@ -36,6 +38,8 @@ func (w *Web) Router() http.Handler {
 	r.Get("/", w.home)
 	r.Get("/server/{id}", w.serverDetail)
 	r.Get("/search", w.search)
+	r.Get("/clusters", w.clusters)
+	r.Get("/mutations/{id}", w.mutationDiff)
 
 	return r
 }
@@ -43,12 +47,12 @@ func (w *Web) Router() http.Handler {
 // home renders the homepage
 func (w *Web) home(wr http.ResponseWriter, r *http.Request) {
 	stats, _ := w.db.GetStats(r.Context())
-	criticalScans, _ := w.db.GetRecentCriticalScans(r.Context(), 10)
+	openedFindings, _ := w.db.GetRecentlyOpenedFindings(r.Context(), "critical", 10)
 	recentMutations, _ := w.db.GetRecentMutations(r.Context(), 10)
 
 	data := map[string]interface{}{
 		"Stats":            stats,
-		"CriticalScans":    criticalScans,
+		"OpenedFindings":   openedFindings,
 		"RecentMutations":  recentMutations,
 	}
 
@@ -74,6 +78,7 @@ func (w *Web) serverDetail(wr http.ResponseWriter, r *http.Request) {
 	scans, _, _ := w.db.GetScanHistory(r.Context(), id, 10, 0)
 	mutations, _, _ := w.db.GetMutationsForServer(r.Context(), id, 10, 0)
 	tools, _ := w.db.GetToolDefinitionsForServer(r.Context(), id)
+	similarityFlags, _ := w.db.GetSimilarityFlagsForServer(r.Context(), id)
 
 	data := map[string]interface{}{
 		"Server":      server,
@@ -81,6 +86,7 @@ func (w *Web) serverDetail(wr http.ResponseWriter, r *http.Request) {
 		"Scans":       scans,
 		"Mutations":   mutations,
 		"Tools":       tools,
+		"SimilarityFlags": similarityFlags,
 	}
 
 	w.templates.ExecuteTemplate(wr, "server.html", data)
@@ -104,3 +110,45 @@ func (w *Web) search(wr http.ResponseWriter, r *http.Request) {
 
 	w.templates.ExecuteTemplate(wr, "search.html", data)
 }
+
+// clusters renders clusters of near-duplicate tool descriptions
+func (w *Web) clusters(wr http.ResponseWriter, r *http.Request) {
+	index, _ := w.db.GetToolIndex(r.Context())
+
+	data := map[string]interface{}{
+		"Clusters": scanner.DescriptionClusters(index),
+	}
+
+	w.templates.ExecuteTemplate(wr, "clusters.html", data)
+}
+
+// mutationDiff renders a mutation with its description and parameter changes highlighted
+func (w *Web) mutationDiff(wr http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(wr, "Invalid mutation ID", http.StatusBadRequest)
+		return
+	}
+
+	mutation, err := w.db.GetMutation(r.Context(), id)
+	if err != nil {
+		http.Error(wr, "Mutation not found", http.StatusNotFound)
+		return
+	}
+
+	// Mutations detected before diffs were stored get one computed now
+	diff := &scanner.MutationDiff{}
+	if mutation.Diff == nil || json.Unmarshal(mutation.Diff, diff) != nil {
+		diff = scanner.DiffMutation(mutation)
+	}
+	server, _ := w.db.GetServer(r.Context(), mutation.ServerID)
+
+	data := map[string]interface{}{
+		"Mutation": mutation,
+		"Diff":     diff,
+		"Server":   server,
+	}
+
+	w.templates.ExecuteTemplate(wr, "mutation.html", data)
+}
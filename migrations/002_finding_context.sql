@@ -0,0 +1,10 @@
+-- Finding context: file classification and entrypoints per scan
+-- Run this with: psql -d mcpsek -f migrations/002_finding_context.sql
+
+ALTER TABLE scans ADD COLUMN IF NOT EXISTS context_details JSONB DEFAULT '{}';
+-- Expected JSON structure:
+-- {
+--   "entrypoints": ["src/index.ts"],      -- files referenced by package.json / pyproject.toml
+--   "file_classes": {"production": 40, "test": 12, "example": 3, "docs": 5},
+--   "reachability": {"reachable": 18, "unreachable": 7}   -- omitted when no entrypoint was found
+-- }
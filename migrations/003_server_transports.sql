@@ -0,0 +1,9 @@
+-- Multi-transport detection: servers can offer several transports at once
+-- Run this with: psql -d mcpsek -f migrations/003_server_transports.sql
+
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS transports TEXT[] DEFAULT '{}';
+-- Values: 'stdio', 'sse', 'streamable_http', 'websocket', 'http' (HTTP server without a recognised MCP transport)
+-- servers.transport keeps the primary (most exposed) transport
+
+UPDATE servers SET transports = ARRAY[transport]
+WHERE transport IS NOT NULL AND transport <> 'unknown' AND transports = '{}';
@@ -0,0 +1,22 @@
+-- Observed internet exposure from external scanners (Shodan)
+-- Run this with: psql -d mcpsek -f migrations/004_observed_exposure.sql
+
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS observed_exposure JSONB;
+-- Expected JSON structure:
+-- {
+--   "source": "shodan",
+--   "checked_at": "2025-01-01T00:00:00Z",
+--   "queries": ["port:3000 \"mcp-session-id\""],
+--   "total_matches": 42,
+--   "instances": [{"ip": "203.0.113.7", "port": 3000, "tls": false, "signature": "mcp-session-id",
+--                  "linked_by": "my-mcp-server", "last_seen": "..."}]
+-- }
+
+-- Cached lookup responses, shared by every server using the same query
+CREATE TABLE IF NOT EXISTS exposure_cache (
+    source      TEXT NOT NULL,
+    query       TEXT NOT NULL,
+    response    JSONB NOT NULL,
+    fetched_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (source, query)
+);
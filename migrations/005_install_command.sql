@@ -0,0 +1,5 @@
+-- Install command extracted from the README's client config snippet or shell instructions
+-- Run this with: psql -d mcpsek -f migrations/005_install_command.sql
+
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS install_command TEXT;
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS install_warnings TEXT[];
@@ -0,0 +1,10 @@
+-- Text returned by tool handlers, tracked for output mutations
+-- Run this with: psql -d mcpsek -f migrations/006_tool_outputs.sql
+
+ALTER TABLE tool_definitions ADD COLUMN IF NOT EXISTS outputs JSONB;       -- [{"text": "...", "line": 12}]
+ALTER TABLE tool_definitions ADD COLUMN IF NOT EXISTS output_hash TEXT;    -- SHA256 of the output texts
+
+-- 'description' for changes to a tool's definition, 'output' for changes to what it returns
+ALTER TABLE mutations ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'description';
+ALTER TABLE mutations ADD COLUMN IF NOT EXISTS old_outputs JSONB;
+ALTER TABLE mutations ADD COLUMN IF NOT EXISTS new_outputs JSONB;
@@ -0,0 +1,4 @@
+-- Whether a server's tool definitions can change at runtime without a new release
+-- Run this with: psql -d mcpsek -f migrations/007_mutation_risk.sql
+
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS mutation_risk TEXT;  -- 'static', 'runtime', NULL until scanned
@@ -0,0 +1,4 @@
+-- Supports the ecosystem-wide tool index used for shadowing and collision detection
+-- Run this with: psql -d mcpsek -f migrations/008_tool_index.sql
+
+CREATE INDEX IF NOT EXISTS idx_tool_defs_server_last_seen ON tool_definitions(server_id, last_seen DESC);
@@ -0,0 +1,25 @@
+-- Near-duplicate description detection (MinHash + LSH) and priority rescans of clones
+-- Run this with: psql -d mcpsek -f migrations/009_similarity.sql
+
+ALTER TABLE tool_definitions ADD COLUMN IF NOT EXISTS minhash BIGINT[];  -- 128 MinHash values, NULL for short descriptions
+
+-- One LSH bucket per band; tools sharing any bucket are similarity candidates
+CREATE TABLE IF NOT EXISTS tool_lsh_buckets (
+    tool_id     UUID NOT NULL REFERENCES tool_definitions(id) ON DELETE CASCADE,
+    band        SMALLINT NOT NULL,
+    bucket      BIGINT NOT NULL,
+    PRIMARY KEY (tool_id, band)
+);
+CREATE INDEX IF NOT EXISTS idx_tool_lsh_buckets_bucket ON tool_lsh_buckets(band, bucket);
+
+-- Tools flagged for review because they are near-duplicates of a critical tool
+CREATE TABLE IF NOT EXISTS similarity_flags (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tool_id         UUID NOT NULL REFERENCES tool_definitions(id) ON DELETE CASCADE,
+    source_tool_id  UUID NOT NULL REFERENCES tool_definitions(id) ON DELETE CASCADE,  -- The critical tool
+    similarity      REAL NOT NULL,
+    flagged_at      TIMESTAMPTZ DEFAULT NOW(),
+    reviewed_at     TIMESTAMPTZ,
+    UNIQUE(tool_id, source_tool_id)
+);
+CREATE INDEX IF NOT EXISTS idx_similarity_flags_tool ON similarity_flags(tool_id);
@@ -0,0 +1,9 @@
+-- Which commit, branch and package version each scan analysed
+-- Run this with: psql -d mcpsek -f migrations/010_scan_revision.sql
+
+ALTER TABLE scans ADD COLUMN IF NOT EXISTS commit_sha TEXT;
+ALTER TABLE scans ADD COLUMN IF NOT EXISTS branch TEXT;           -- The repository's default branch
+ALTER TABLE scans ADD COLUMN IF NOT EXISTS ref TEXT;              -- Tag, branch or commit of an on-demand scan; NULL for scheduled scans
+ALTER TABLE scans ADD COLUMN IF NOT EXISTS package_version TEXT;  -- Version in the package manifest at the scanned commit
+
+CREATE INDEX IF NOT EXISTS idx_scans_server_version ON scans(server_id, package_version, scanned_at DESC);
@@ -0,0 +1,35 @@
+-- Per-scan tool snapshots: the exact tool set each scan saw
+-- Run this with: psql -d mcpsek -f migrations/011_scan_tools.sql
+
+CREATE TABLE IF NOT EXISTS scan_tools (
+    scan_id     UUID NOT NULL REFERENCES scans(id) ON DELETE CASCADE,
+    tool_id     UUID NOT NULL REFERENCES tool_definitions(id) ON DELETE CASCADE,
+    outputs     JSONB,                    -- Outputs change without changing content_hash, so they're per scan
+    output_hash TEXT,
+    PRIMARY KEY (scan_id, tool_id)
+);
+CREATE INDEX IF NOT EXISTS idx_scan_tools_tool ON scan_tools(tool_id);
+
+-- Set once the snapshot is written, so a scan that found no tools still has one
+ALTER TABLE scans ADD COLUMN IF NOT EXISTS tools_recorded BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_scans_server_scanned ON scans(server_id, scanned_at DESC);
+
+-- The scans a mutation was detected between
+ALTER TABLE mutations ADD COLUMN IF NOT EXISTS scan_id UUID REFERENCES scans(id) ON DELETE SET NULL;
+ALTER TABLE mutations ADD COLUMN IF NOT EXISTS previous_scan_id UUID REFERENCES scans(id) ON DELETE SET NULL;
+
+-- Backfill: each server's latest scan gets the tools last seen with it
+INSERT INTO scan_tools (scan_id, tool_id, outputs, output_hash)
+SELECT latest.id, t.id, t.outputs, t.output_hash
+FROM tool_definitions t
+JOIN LATERAL (
+    SELECT s.id FROM scans s
+    WHERE s.server_id = t.server_id AND s.ref IS NULL
+    ORDER BY s.scanned_at DESC
+    LIMIT 1
+) latest ON TRUE
+WHERE t.last_seen = (SELECT MAX(last_seen) FROM tool_definitions l WHERE l.server_id = t.server_id)
+ON CONFLICT DO NOTHING;
+
+UPDATE scans SET tools_recorded = TRUE
+WHERE id IN (SELECT DISTINCT scan_id FROM scan_tools);
@@ -0,0 +1,5 @@
+-- Structured diffs of mutations: word-level description diff, parameter changes and findings in added text
+-- Run this with: psql -d mcpsek -f migrations/012_mutation_diffs.sql
+
+-- Mutations detected before this migration have no stored diff; the API computes it from the old and new columns
+ALTER TABLE mutations ADD COLUMN IF NOT EXISTS diff JSONB;
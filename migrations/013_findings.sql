@@ -0,0 +1,35 @@
+-- Finding lifecycle: each finding is tracked across scans by a stable fingerprint
+-- Run this with: psql -d mcpsek -f migrations/013_findings.sql
+
+CREATE TABLE IF NOT EXISTS findings (
+    id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    server_id          UUID NOT NULL REFERENCES servers(id) ON DELETE CASCADE,
+    fingerprint        TEXT NOT NULL,             -- Hash of check, rule and what the finding is about; no line numbers
+    check_name         TEXT NOT NULL,             -- "tool_integrity", "auth" or "exposure"
+    rule               TEXT NOT NULL,
+    severity           TEXT NOT NULL,             -- "warning" or "critical", as of the latest scan that saw it
+    tool_name          TEXT,
+    file_path          TEXT,
+    line               INTEGER,
+    message            TEXT NOT NULL,
+    first_seen         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    opened_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(), -- first_seen, or when a fixed finding came back
+    fixed_at           TIMESTAMPTZ,               -- NULL while open
+    first_seen_scan_id UUID REFERENCES scans(id) ON DELETE SET NULL,
+    last_seen_scan_id  UUID REFERENCES scans(id) ON DELETE SET NULL,
+    fixed_scan_id      UUID REFERENCES scans(id) ON DELETE SET NULL,
+    UNIQUE (server_id, fingerprint)
+);
+CREATE INDEX IF NOT EXISTS idx_findings_opened ON findings(opened_at DESC) WHERE fixed_at IS NULL;
+
+-- Which findings each scan saw, for comparing any two scans
+CREATE TABLE IF NOT EXISTS scan_findings (
+    scan_id     UUID NOT NULL REFERENCES scans(id) ON DELETE CASCADE,
+    finding_id  UUID NOT NULL REFERENCES findings(id) ON DELETE CASCADE,
+    PRIMARY KEY (scan_id, finding_id)
+);
+CREATE INDEX IF NOT EXISTS idx_scan_findings_finding ON scan_findings(finding_id);
+
+-- Set once a scan's findings are recorded; earlier scans can't be compared
+ALTER TABLE scans ADD COLUMN IF NOT EXISTS findings_recorded BOOLEAN NOT NULL DEFAULT FALSE;